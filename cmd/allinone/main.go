@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/domain"
+	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/transport/bus"
+	server "stockseer.ai/blueksy-firehose/internal/transport/http"
+	"stockseer.ai/blueksy-firehose/internal/transport/memory"
+	"stockseer.ai/blueksy-firehose/internal/transport/ws"
+)
+
+// The all-in-one binary runs the websocket reader and the server in one process,
+// connected by an in-memory bus instead of a broker.
+func main() {
+	// Load our configuration on start up.
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load configuration: %s ", err))
+	}
+	cfg.MessageBus = bus.BackendMemory
+
+	// Create full app context with MongoDB connection first
+	appContext := appcontext.NewAppContext(cfg, false, nil) // Create context with MongoDB first
+	log := appContext.Log
+
+	// Nothing consumes the dead letter topic of the in-memory bus, dead letters
+	// published to it would be lost.
+	if cfg.DeadLetterSink == models.DeadLetterSinkMQTT {
+		log.Error(
+			"Failed to initialize message bus client",
+			fmt.Errorf("dead letter sink %q is not supported in process, use %q", cfg.DeadLetterSink, models.DeadLetterSinkMongo),
+		)
+		os.Exit(1)
+	}
+
+	// initialize our processors that govern what data to consume
+	processors, err := domain.InitProcessors(cfg, appContext.ClassificationStore)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize text processors: %s ", err))
+	}
+
+	// A single client publishes the posts of the reader and processes them, the
+	// bus buffers a queue's worth of posts between the two.
+	busClient, err := bus.NewClientWithBus(
+		appContext,
+		memory.NewMemoryBus(cfg.BusQueueSize),
+		"allinone",
+		processors,
+	)
+	if err != nil {
+		log.Error("Failed to initialize message bus client", err)
+		os.Exit(1)
+	}
+
+	// add message bus client to app context
+	appContext.MessageClient = busClient
+
+	// subscribe before the reader publishes, posts without subscriber are dropped
+	if err := busClient.Subscribe(); err != nil {
+		log.Error("Failed to start message consumption", err)
+		os.Exit(1)
+	}
+
+	// add data collector for the metrics of the reader
+	dc := domain.NewDataCollector(appContext)
+	dc.PublishTo(busClient.PublishMetrics)
+
+	if cfg.DevMode {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	} else {
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	}
+
+	const devModeFormat = "DEV MODE: %s"
+	log.Info(devModeFormat, strconv.FormatBool(cfg.DevMode))
+
+	// Create a context cancelled on SIGINT or SIGTERM.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	ctx = appcontext.ContextWithAppContext(ctx, appContext)
+
+	// Start our server in a separate goroutine.
+	go func() {
+		servererr := server.StartServer(ctx)
+
+		if servererr != nil {
+			log.Error("server failed to start", servererr)
+		}
+	}()
+
+	// initialize our rules that govern what data to consume
+	rules := domain.InitRules(cfg)
+
+	log.Info("Starting WebSocket client...")
+	// the reader publishes to the bus until the process exits
+	go ws.StartWebSocketClient(ctx, rules, dc)
+
+	// Process the queued messages before exiting on shutdown.
+	<-ctx.Done()
+	log.Info("Shutting down...")
+	busClient.Disconnect()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/repositories"
+)
+
+// migrate stores the dates of the posts and metrics stored by earlier versions as
+// BSON dates, the datetime and created_at fields of the posts and the time field of
+// the metrics. It only updates the documents still missing them, so it is safe to run
+// again.
+func main() {
+	batchSize := flag.Int("batch", 1000, "number of posts updated together")
+	flag.Parse()
+	if *batchSize < 1 {
+		fmt.Fprintf(os.Stderr, "-batch must be at least 1, got %d\n", *batchSize)
+		os.Exit(2)
+	}
+
+	// Load our configuration on start up.
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load configuration: %s ", err))
+	}
+
+	appContext := appcontext.NewAppContext(cfg, false, nil)
+	log := appContext.Log
+	db := appContext.MongoClient.Database(cfg.MongoDatabase)
+	defer appContext.MongoClient.Disconnect(context.Background())
+
+	messages, err := repositories.MigrateMessageDates(
+		context.Background(),
+		db.Collection(cfg.MongoMessagesCollection),
+		*batchSize,
+	)
+	log.Info("Migrated the dates of %d posts", messages)
+	if err != nil {
+		log.Error("Failed to migrate the dates of the posts", err)
+		os.Exit(1)
+	}
+
+	metrics, err := repositories.MigrateMetricsTime(
+		context.Background(),
+		db.Collection(cfg.MongoMetricsCollection),
+	)
+	log.Info("Migrated the time of %d metrics", metrics)
+	if err != nil {
+		log.Error("Failed to migrate the time of the metrics", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/transport/bus"
+)
+
+// replay publishes dead-lettered messages back to the topic they were received on so
+// the servers process them again, once the cause of the failures is fixed.
+func main() {
+	limit := flag.Int("limit", 0, "maximum number of messages to replay, 0 replays all of them")
+	idle := flag.Duration(
+		"idle",
+		10*time.Second,
+		"with the mqtt sink, stop once no dead letter arrived for this long",
+	)
+	flag.Parse()
+
+	// Load our configuration on start up.
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load configuration: %s ", err))
+	}
+
+	appContext := appcontext.NewAppContext(cfg, false, nil)
+	log := appContext.Log
+
+	busClient, err := bus.NewClient(appContext, "replay", nil)
+	if err != nil {
+		log.Error("Failed to initialize message bus client", err)
+		os.Exit(1)
+	}
+	if busClient == nil {
+		log.Error("MQTT must be enabled to replay dead letters", nil)
+		os.Exit(1)
+	}
+	defer busClient.Disconnect()
+
+	replayed, err := busClient.ReplayDeadLetters(*limit, *idle)
+	log.Info("Replayed %d dead lettered messages", replayed)
+	if err != nil {
+		log.Error("Failed to replay dead letters", err)
+		os.Exit(1)
+	}
+}
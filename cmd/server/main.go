@@ -4,14 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 
 	"github.com/rs/zerolog"
 	"stockseer.ai/blueksy-firehose/internal/appcontext"
 	"stockseer.ai/blueksy-firehose/internal/config"
 	"stockseer.ai/blueksy-firehose/internal/domain"
+	"stockseer.ai/blueksy-firehose/internal/transport/bus"
 	server "stockseer.ai/blueksy-firehose/internal/transport/http"
-	"stockseer.ai/blueksy-firehose/internal/transport/mqtt"
 )
 
 func main() {
@@ -21,24 +23,27 @@ func main() {
 		panic(fmt.Sprintf("Failed to load configuration: %s ", err))
 	}
 
-	// initialize our processors that govern what data to consume
-	processors := domain.InitProcessors(cfg)
-
 	// Create full app context with MongoDB connection first
 	appContext := appcontext.NewAppContext(cfg, false, nil) // Create context with MongoDB first
 	log := appContext.Log
 
-	// Initialize MQTT client if enabled, ensures that we can connect...
-	mqttClient, err := mqtt.NewMqttClient(
+	// initialize our processors that govern what data to consume
+	processors, err := domain.InitProcessors(cfg, appContext.ClassificationStore)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to initialize text processors: %s ", err))
+	}
+
+	// Initialize the message bus client if enabled, ensures that we can connect...
+	busClient, err := bus.NewClient(
 		appContext,
 		"server",
 		processors,
 	) // Pass full app context with MongoDB
 	if err != nil {
-		log.Error("Failed to initialize MQTT client: %v", err)
+		log.Error("Failed to initialize message bus client", err)
 		os.Exit(1)
 	}
-	defer mqttClient.Disconnect() // Ensure client disconnects on exit
+	defer busClient.Disconnect() // Ensure client disconnects on exit
 
 	if cfg.DevMode {
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
@@ -49,8 +54,8 @@ func main() {
 	const devModeFormat = "DEV MODE: %s"
 	log.Info(devModeFormat, strconv.FormatBool(cfg.DevMode))
 
-	// Create a context with cancellation.
-	ctx, cancel := context.WithCancel(context.Background())
+	// Create a context cancelled on SIGINT or SIGTERM.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	ctx = appcontext.ContextWithAppContext(ctx, appContext)
@@ -64,12 +69,16 @@ func main() {
 		}
 	}()
 
-	// consume messages from MQTT
-	if err := mqttClient.ConsumeMessages(); err != nil { // Call directly on the concrete instance
-		appContext.Log.Error("Failed to start MQTT message consumption: %v", err)
+	// Process the queued messages before exiting on shutdown.
+	go func() {
+		<-ctx.Done()
+		log.Info("Shutting down...")
+		busClient.Disconnect()
+	}()
+
+	// consume messages from the bus until the client is disconnected
+	if err := busClient.ConsumeMessages(); err != nil { // Call directly on the concrete instance
+		appContext.Log.Error("Failed to start message consumption", err)
 		os.Exit(1)
 	}
-
-	// Wait forever to keep the main goroutine running
-	select {}
 }
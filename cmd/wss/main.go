@@ -10,7 +10,7 @@ import (
 	"stockseer.ai/blueksy-firehose/internal/appcontext"
 	"stockseer.ai/blueksy-firehose/internal/config"
 	"stockseer.ai/blueksy-firehose/internal/domain"
-	"stockseer.ai/blueksy-firehose/internal/transport/mqtt"
+	"stockseer.ai/blueksy-firehose/internal/transport/bus"
 	"stockseer.ai/blueksy-firehose/internal/transport/ws"
 )
 
@@ -25,20 +25,37 @@ func main() {
 	appContext := appcontext.NewAppContext(cfg, false, nil) // Create context with MongoDB first
 	log := appContext.Log
 
-	// Initialize MQTT client if enabled, ensures that we can connect...
-	mqttClient, err := mqtt.NewMqttClient(
+	// Initialize the message bus client if enabled, ensures that we can connect...
+	busClient, err := bus.NewClient(
 		appContext,
-		"server",
+		"wss",
 		nil,
 	) // Pass full app context with MongoDB
 	if err != nil {
-		log.Error("Failed to initialize MQTT client: %v", err)
+		log.Error("Failed to initialize message bus client", err)
 		os.Exit(1)
 	}
-	defer mqttClient.Disconnect() // Ensure client disconnects on exit
+	defer busClient.Disconnect() // Ensure client disconnects on exit
 
-	// add mqtt client to app context
-	appContext.MQTTClient = mqttClient
+	// add message bus client to app context
+	appContext.MessageClient = busClient
+
+	// add data collector for metrics collection
+	dc := domain.NewDataCollector(appContext)
+
+	// buffer posts on disk while the broker is unreachable
+	if cfg.BusSpoolDir != "" && busClient != nil {
+		if err := busClient.EnableSpool(); err != nil {
+			log.Error("Failed to open message spool", err)
+			os.Exit(1)
+		}
+		dc.WatchSpool(busClient.SpoolStats)
+	}
+
+	// publish the metrics of every interval next to logging them
+	if busClient != nil {
+		dc.PublishTo(busClient.PublishMetrics)
+	}
 
 	if cfg.DevMode {
 		zerolog.SetGlobalLevel(zerolog.DebugLevel)
@@ -60,5 +77,5 @@ func main() {
 
 	log.Info("Starting WebSocket client...")
 	// start our web socket client to receive messages with our rules
-	ws.StartWebSocketClient(ctx, rules)
+	ws.StartWebSocketClient(ctx, rules, dc)
 }
@@ -0,0 +1,49 @@
+package mlclassifier
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// API is implemented by every transport able to talk to a classifier service.
+type API interface {
+	Classify(requestData DataRequest) (*[]DataResponseItem, error)
+	ClassifyTopK(requestData DataRequest) (*[][]DataResponseItem, error)
+}
+
+// ModelInfo describes the model served by a classifier service.
+type ModelInfo struct {
+	Name      string
+	Version   string
+	Labels    []string
+	MaxTokens int
+}
+
+// ModelInfoProvider is implemented by transports that can describe their model.
+type ModelInfoProvider interface {
+	ModelInfo() (*ModelInfo, error)
+}
+
+var (
+	_ API               = (*Client)(nil)
+	_ API               = (*GRPCClient)(nil)
+	_ ModelInfoProvider = (*GRPCClient)(nil)
+)
+
+// NewClientForURL picks the transport from the URL scheme: grpc:// and grpcs:// use
+// the gRPC protocol, anything else the JSON /classify endpoint.
+func NewClientForURL(rawURL string) (API, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing classifier url %s: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "grpc":
+		return NewGRPCClient(u.Host, false)
+	case "grpcs":
+		return NewGRPCClient(u.Host, true)
+	default:
+		return NewClient(rawURL), nil
+	}
+}
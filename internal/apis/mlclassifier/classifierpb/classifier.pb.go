@@ -0,0 +1,564 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: internal/apis/mlclassifier/classifierpb/classifier.proto
+
+// Version 1 of the contract between the Go server and the classifier services.
+// Breaking changes must go into a new package (v2) so both can be served side by side.
+
+package classifierpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type HealthResponse_Status int32
+
+const (
+	HealthResponse_UNKNOWN     HealthResponse_Status = 0
+	HealthResponse_SERVING     HealthResponse_Status = 1
+	HealthResponse_NOT_SERVING HealthResponse_Status = 2
+)
+
+// Enum value maps for HealthResponse_Status.
+var (
+	HealthResponse_Status_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "SERVING",
+		2: "NOT_SERVING",
+	}
+	HealthResponse_Status_value = map[string]int32{
+		"UNKNOWN":     0,
+		"SERVING":     1,
+		"NOT_SERVING": 2,
+	}
+)
+
+func (x HealthResponse_Status) Enum() *HealthResponse_Status {
+	p := new(HealthResponse_Status)
+	*p = x
+	return p
+}
+
+func (x HealthResponse_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HealthResponse_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_enumTypes[0].Descriptor()
+}
+
+func (HealthResponse_Status) Type() protoreflect.EnumType {
+	return &file_internal_apis_mlclassifier_classifierpb_classifier_proto_enumTypes[0]
+}
+
+func (x HealthResponse_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HealthResponse_Status.Descriptor instead.
+func (HealthResponse_Status) EnumDescriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{7, 0}
+}
+
+type ClassifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Texts []string               `protobuf:"bytes,1,rep,name=texts,proto3" json:"texts,omitempty"`
+	// Number of labels wanted per text, 0 means only the best one.
+	TopK          int32 `protobuf:"varint,2,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClassifyRequest) Reset() {
+	*x = ClassifyRequest{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyRequest) ProtoMessage() {}
+
+func (x *ClassifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassifyRequest.ProtoReflect.Descriptor instead.
+func (*ClassifyRequest) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ClassifyRequest) GetTexts() []string {
+	if x != nil {
+		return x.Texts
+	}
+	return nil
+}
+
+func (x *ClassifyRequest) GetTopK() int32 {
+	if x != nil {
+		return x.TopK
+	}
+	return 0
+}
+
+type ScoredLabel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Label         string                 `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScoredLabel) Reset() {
+	*x = ScoredLabel{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScoredLabel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoredLabel) ProtoMessage() {}
+
+func (x *ScoredLabel) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoredLabel.ProtoReflect.Descriptor instead.
+func (*ScoredLabel) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScoredLabel) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *ScoredLabel) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type Prediction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Labels ordered by descending score.
+	Labels        []*ScoredLabel `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prediction) Reset() {
+	*x = Prediction{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prediction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prediction) ProtoMessage() {}
+
+func (x *Prediction) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prediction.ProtoReflect.Descriptor instead.
+func (*Prediction) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Prediction) GetLabels() []*ScoredLabel {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type ClassifyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Predictions   []*Prediction          `protobuf:"bytes,1,rep,name=predictions,proto3" json:"predictions,omitempty"`
+	ModelVersion  string                 `protobuf:"bytes,2,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClassifyResponse) Reset() {
+	*x = ClassifyResponse{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyResponse) ProtoMessage() {}
+
+func (x *ClassifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassifyResponse.ProtoReflect.Descriptor instead.
+func (*ClassifyResponse) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ClassifyResponse) GetPredictions() []*Prediction {
+	if x != nil {
+		return x.Predictions
+	}
+	return nil
+}
+
+func (x *ClassifyResponse) GetModelVersion() string {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return ""
+}
+
+type ModelInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelInfoRequest) Reset() {
+	*x = ModelInfoRequest{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelInfoRequest) ProtoMessage() {}
+
+func (x *ModelInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelInfoRequest.ProtoReflect.Descriptor instead.
+func (*ModelInfoRequest) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{4}
+}
+
+type ModelInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Labels        []string               `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty"`
+	MaxTokens     int32                  `protobuf:"varint,4,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelInfo) Reset() {
+	*x = ModelInfo{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelInfo) ProtoMessage() {}
+
+func (x *ModelInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelInfo.ProtoReflect.Descriptor instead.
+func (*ModelInfo) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ModelInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ModelInfo) GetMaxTokens() int32 {
+	if x != nil {
+		return x.MaxTokens
+	}
+	return 0
+}
+
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{6}
+}
+
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        HealthResponse_Status  `protobuf:"varint,1,opt,name=status,proto3,enum=stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponse_Status" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HealthResponse) GetStatus() HealthResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return HealthResponse_UNKNOWN
+}
+
+var File_internal_apis_mlclassifier_classifierpb_classifier_proto protoreflect.FileDescriptor
+
+const file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDesc = "" +
+	"\n" +
+	"8internal/apis/mlclassifier/classifierpb/classifier.proto\x12-stockseer.ai.blueksy.firehose.mlclassifier.v1\"<\n" +
+	"\x0fClassifyRequest\x12\x14\n" +
+	"\x05texts\x18\x01 \x03(\tR\x05texts\x12\x13\n" +
+	"\x05top_k\x18\x02 \x01(\x05R\x04topK\"9\n" +
+	"\vScoredLabel\x12\x14\n" +
+	"\x05label\x18\x01 \x01(\tR\x05label\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\"`\n" +
+	"\n" +
+	"Prediction\x12R\n" +
+	"\x06labels\x18\x01 \x03(\v2:.stockseer.ai.blueksy.firehose.mlclassifier.v1.ScoredLabelR\x06labels\"\x94\x01\n" +
+	"\x10ClassifyResponse\x12[\n" +
+	"\vpredictions\x18\x01 \x03(\v29.stockseer.ai.blueksy.firehose.mlclassifier.v1.PredictionR\vpredictions\x12#\n" +
+	"\rmodel_version\x18\x02 \x01(\tR\fmodelVersion\"\x12\n" +
+	"\x10ModelInfoRequest\"p\n" +
+	"\tModelInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06labels\x18\x03 \x03(\tR\x06labels\x12\x1d\n" +
+	"\n" +
+	"max_tokens\x18\x04 \x01(\x05R\tmaxTokens\"\x0f\n" +
+	"\rHealthRequest\"\xa3\x01\n" +
+	"\x0eHealthResponse\x12\\\n" +
+	"\x06status\x18\x01 \x01(\x0e2D.stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponse.StatusR\x06status\"3\n" +
+	"\x06Status\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\v\n" +
+	"\aSERVING\x10\x01\x12\x0f\n" +
+	"\vNOT_SERVING\x10\x022\xae\x03\n" +
+	"\n" +
+	"Classifier\x12\x8b\x01\n" +
+	"\bClassify\x12>.stockseer.ai.blueksy.firehose.mlclassifier.v1.ClassifyRequest\x1a?.stockseer.ai.blueksy.firehose.mlclassifier.v1.ClassifyResponse\x12\x89\x01\n" +
+	"\fGetModelInfo\x12?.stockseer.ai.blueksy.firehose.mlclassifier.v1.ModelInfoRequest\x1a8.stockseer.ai.blueksy.firehose.mlclassifier.v1.ModelInfo\x12\x85\x01\n" +
+	"\x06Health\x12<.stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthRequest\x1a=.stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponseBGZEstockseer.ai/blueksy-firehose/internal/apis/mlclassifier/classifierpbb\x06proto3"
+
+var (
+	file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescOnce sync.Once
+	file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescData []byte
+)
+
+func file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescGZIP() []byte {
+	file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescOnce.Do(func() {
+		file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDesc), len(file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDesc)))
+	})
+	return file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDescData
+}
+
+var file_internal_apis_mlclassifier_classifierpb_classifier_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_internal_apis_mlclassifier_classifierpb_classifier_proto_goTypes = []any{
+	(HealthResponse_Status)(0), // 0: stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponse.Status
+	(*ClassifyRequest)(nil),    // 1: stockseer.ai.blueksy.firehose.mlclassifier.v1.ClassifyRequest
+	(*ScoredLabel)(nil),        // 2: stockseer.ai.blueksy.firehose.mlclassifier.v1.ScoredLabel
+	(*Prediction)(nil),         // 3: stockseer.ai.blueksy.firehose.mlclassifier.v1.Prediction
+	(*ClassifyResponse)(nil),   // 4: stockseer.ai.blueksy.firehose.mlclassifier.v1.ClassifyResponse
+	(*ModelInfoRequest)(nil),   // 5: stockseer.ai.blueksy.firehose.mlclassifier.v1.ModelInfoRequest
+	(*ModelInfo)(nil),          // 6: stockseer.ai.blueksy.firehose.mlclassifier.v1.ModelInfo
+	(*HealthRequest)(nil),      // 7: stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthRequest
+	(*HealthResponse)(nil),     // 8: stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponse
+}
+var file_internal_apis_mlclassifier_classifierpb_classifier_proto_depIdxs = []int32{
+	2, // 0: stockseer.ai.blueksy.firehose.mlclassifier.v1.Prediction.labels:type_name -> stockseer.ai.blueksy.firehose.mlclassifier.v1.ScoredLabel
+	3, // 1: stockseer.ai.blueksy.firehose.mlclassifier.v1.ClassifyResponse.predictions:type_name -> stockseer.ai.blueksy.firehose.mlclassifier.v1.Prediction
+	0, // 2: stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponse.status:type_name -> stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponse.Status
+	1, // 3: stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier.Classify:input_type -> stockseer.ai.blueksy.firehose.mlclassifier.v1.ClassifyRequest
+	5, // 4: stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier.GetModelInfo:input_type -> stockseer.ai.blueksy.firehose.mlclassifier.v1.ModelInfoRequest
+	7, // 5: stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier.Health:input_type -> stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthRequest
+	4, // 6: stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier.Classify:output_type -> stockseer.ai.blueksy.firehose.mlclassifier.v1.ClassifyResponse
+	6, // 7: stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier.GetModelInfo:output_type -> stockseer.ai.blueksy.firehose.mlclassifier.v1.ModelInfo
+	8, // 8: stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier.Health:output_type -> stockseer.ai.blueksy.firehose.mlclassifier.v1.HealthResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_internal_apis_mlclassifier_classifierpb_classifier_proto_init() }
+func file_internal_apis_mlclassifier_classifierpb_classifier_proto_init() {
+	if File_internal_apis_mlclassifier_classifierpb_classifier_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDesc), len(file_internal_apis_mlclassifier_classifierpb_classifier_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_apis_mlclassifier_classifierpb_classifier_proto_goTypes,
+		DependencyIndexes: file_internal_apis_mlclassifier_classifierpb_classifier_proto_depIdxs,
+		EnumInfos:         file_internal_apis_mlclassifier_classifierpb_classifier_proto_enumTypes,
+		MessageInfos:      file_internal_apis_mlclassifier_classifierpb_classifier_proto_msgTypes,
+	}.Build()
+	File_internal_apis_mlclassifier_classifierpb_classifier_proto = out.File
+	file_internal_apis_mlclassifier_classifierpb_classifier_proto_goTypes = nil
+	file_internal_apis_mlclassifier_classifierpb_classifier_proto_depIdxs = nil
+}
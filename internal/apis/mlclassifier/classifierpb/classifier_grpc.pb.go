@@ -0,0 +1,192 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.29.3
+// source: internal/apis/mlclassifier/classifierpb/classifier.proto
+
+// Version 1 of the contract between the Go server and the classifier services.
+// Breaking changes must go into a new package (v2) so both can be served side by side.
+
+package classifierpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Classifier_Classify_FullMethodName     = "/stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier/Classify"
+	Classifier_GetModelInfo_FullMethodName = "/stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier/GetModelInfo"
+	Classifier_Health_FullMethodName       = "/stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier/Health"
+)
+
+// ClassifierClient is the client API for Classifier service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ClassifierClient interface {
+	// Classify labels a batch of texts, one prediction per text in request order.
+	Classify(ctx context.Context, in *ClassifyRequest, opts ...grpc.CallOption) (*ClassifyResponse, error)
+	// GetModelInfo describes the model served behind the endpoint.
+	GetModelInfo(ctx context.Context, in *ModelInfoRequest, opts ...grpc.CallOption) (*ModelInfo, error)
+	// Health reports whether the model is loaded and ready to classify.
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+}
+
+type classifierClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClassifierClient(cc grpc.ClientConnInterface) ClassifierClient {
+	return &classifierClient{cc}
+}
+
+func (c *classifierClient) Classify(ctx context.Context, in *ClassifyRequest, opts ...grpc.CallOption) (*ClassifyResponse, error) {
+	out := new(ClassifyResponse)
+	err := c.cc.Invoke(ctx, Classifier_Classify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *classifierClient) GetModelInfo(ctx context.Context, in *ModelInfoRequest, opts ...grpc.CallOption) (*ModelInfo, error) {
+	out := new(ModelInfo)
+	err := c.cc.Invoke(ctx, Classifier_GetModelInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *classifierClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, Classifier_Health_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClassifierServer is the server API for Classifier service.
+// All implementations must embed UnimplementedClassifierServer
+// for forward compatibility
+type ClassifierServer interface {
+	// Classify labels a batch of texts, one prediction per text in request order.
+	Classify(context.Context, *ClassifyRequest) (*ClassifyResponse, error)
+	// GetModelInfo describes the model served behind the endpoint.
+	GetModelInfo(context.Context, *ModelInfoRequest) (*ModelInfo, error)
+	// Health reports whether the model is loaded and ready to classify.
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	mustEmbedUnimplementedClassifierServer()
+}
+
+// UnimplementedClassifierServer must be embedded to have forward compatible implementations.
+type UnimplementedClassifierServer struct {
+}
+
+func (UnimplementedClassifierServer) Classify(context.Context, *ClassifyRequest) (*ClassifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Classify not implemented")
+}
+func (UnimplementedClassifierServer) GetModelInfo(context.Context, *ModelInfoRequest) (*ModelInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetModelInfo not implemented")
+}
+func (UnimplementedClassifierServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedClassifierServer) mustEmbedUnimplementedClassifierServer() {}
+
+// UnsafeClassifierServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClassifierServer will
+// result in compilation errors.
+type UnsafeClassifierServer interface {
+	mustEmbedUnimplementedClassifierServer()
+}
+
+func RegisterClassifierServer(s grpc.ServiceRegistrar, srv ClassifierServer) {
+	s.RegisterService(&Classifier_ServiceDesc, srv)
+}
+
+func _Classifier_Classify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClassifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClassifierServer).Classify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Classifier_Classify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClassifierServer).Classify(ctx, req.(*ClassifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Classifier_GetModelInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModelInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClassifierServer).GetModelInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Classifier_GetModelInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClassifierServer).GetModelInfo(ctx, req.(*ModelInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Classifier_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClassifierServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Classifier_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClassifierServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Classifier_ServiceDesc is the grpc.ServiceDesc for Classifier service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Classifier_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "stockseer.ai.blueksy.firehose.mlclassifier.v1.Classifier",
+	HandlerType: (*ClassifierServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Classify",
+			Handler:    _Classifier_Classify_Handler,
+		},
+		{
+			MethodName: "GetModelInfo",
+			Handler:    _Classifier_GetModelInfo_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _Classifier_Health_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal/apis/mlclassifier/classifierpb/classifier.proto",
+}
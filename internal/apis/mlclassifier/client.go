@@ -21,39 +21,62 @@ func NewClient(baseURL string) *Client {
 }
 
 func (c *Client) Classify(requestData DataRequest) (*[]DataResponseItem, error) {
+	var dataResponse []DataResponseItem
+	if err := c.post(requestData, &dataResponse); err != nil {
+		return nil, err
+	}
+
+	return &dataResponse, nil
+}
+
+// ClassifyTopK returns the TopK best labels for every requested item.
+func (c *Client) ClassifyTopK(requestData DataRequest) (*[][]DataResponseItem, error) {
+	if requestData.TopK < 1 {
+		return nil, fmt.Errorf("top k must be at least 1, got %d", requestData.TopK)
+	}
+
+	var dataResponse [][]DataResponseItem
+	if err := c.post(requestData, &dataResponse); err != nil {
+		return nil, err
+	}
+
+	return &dataResponse, nil
+}
+
+// post sends the request to the /classify endpoint and decodes the reply into out.
+func (c *Client) post(requestData DataRequest, out interface{}) error {
 	url := fmt.Sprintf("%s/classify", c.BaseURL)
 
 	jsonData, err := json.Marshal(requestData)
 	if err != nil {
-		return nil, fmt.Errorf("marshaling request data: %w", err)
+		return fmt.Errorf("marshaling request data: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return fmt.Errorf("creating request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("making request: %w", err)
+		return fmt.Errorf("making request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return nil, fmt.Errorf("reading response body: %w", err)
+			return fmt.Errorf("reading response body: %w", err)
 		}
 		bodyString := string(bodyBytes)
-		return nil, fmt.Errorf("unexpected status code: %d, response body: %s", resp.StatusCode, bodyString)
+		return fmt.Errorf("unexpected status code: %d, response body: %s", resp.StatusCode, bodyString)
 	}
 
-	var dataResponse []DataResponseItem
-	if err := json.NewDecoder(resp.Body).Decode(&dataResponse); err != nil {
-		return nil, fmt.Errorf("decoding response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
 	}
 
-	return &dataResponse, nil
+	return nil
 }
@@ -2,6 +2,7 @@ package mlclassifier_test
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -25,8 +26,8 @@ func TestClient_Classify_Success(t *testing.T) {
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
-		w.Header().Set("Content-Type", "application/json")  // Important: Set Content-Type header
-		fmt.Println(w, `[{"label": "test", "score": 0.8}]`) // Correct JSON response
+		w.Header().Set("Content-Type", "application/json")   // Important: Set Content-Type header
+		fmt.Fprintln(w, `[{"label": "test", "score": 0.8}]`) // Correct JSON response
 	}))
 	defer testServer.Close()
 
@@ -66,7 +67,7 @@ func TestClient_Classify_Error_Marshal(t *testing.T) {
 
 		// Respond with a mock JSON response
 		w.Header().Set("Content-Type", "application/json")
-		fmt.Println(w, ``)
+		fmt.Fprintln(w, ``)
 	}))
 	defer testServer.Close()
 
@@ -109,7 +110,7 @@ func TestClient_Classify_Error_Non200Status(t *testing.T) {
 		}
 
 		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Println(w, "Internal Server Error")
+		fmt.Fprintln(w, "Internal Server Error")
 	}))
 
 	defer testServer.Close()
@@ -131,3 +132,45 @@ func TestClient_Classify_Error_Non200Status(t *testing.T) {
 		t.Errorf("Unexpected error message: %v", err)
 	}
 }
+
+func TestClient_ClassifyTopK_Success(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"top_k":2`) {
+			t.Errorf("Expected top_k in request body, got %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `[[{"label": "a", "score": 0.7}, {"label": "b", "score": 0.2}]]`)
+	}))
+	defer testServer.Close()
+
+	client := mlclassifier.NewClient(testServer.URL)
+	requestData := mlclassifier.DataRequest{
+		Items: []mlclassifier.DataRequestItem{
+			{Text: "Test"},
+		},
+		TopK: 2,
+	}
+
+	resp, err := client.ClassifyTopK(requestData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(*resp) != 1 || len((*resp)[0]) != 2 {
+		t.Fatalf("Expected 1 item with 2 labels, got %v", *resp)
+	}
+
+	if (*resp)[0][1].Label != "b" || (*resp)[0][1].Score != 0.2 {
+		t.Errorf("Unexpected second label: %+v", (*resp)[0][1])
+	}
+}
+
+func TestClient_ClassifyTopK_Error_InvalidK(t *testing.T) {
+	client := mlclassifier.NewClient("http://localhost")
+
+	_, err := client.ClassifyTopK(mlclassifier.DataRequest{})
+	if err == nil {
+		t.Errorf("Expected error for missing top k")
+	}
+}
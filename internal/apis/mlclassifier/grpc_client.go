@@ -0,0 +1,147 @@
+package mlclassifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier/classifierpb"
+)
+
+// GRPCClient talks to a classifier service over the classifierpb.Classifier protocol.
+type GRPCClient struct {
+	Target  string
+	Timeout time.Duration
+
+	conn   *grpc.ClientConn
+	client classifierpb.ClassifierClient
+}
+
+// NewGRPCClient creates a client for target (host:port). The connection is
+// established lazily on the first call.
+func NewGRPCClient(target string, useTLS bool) (*GRPCClient, error) {
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("dialing classifier %s: %w", target, err)
+	}
+
+	return &GRPCClient{
+		Target:  target,
+		Timeout: 30 * time.Second,
+		conn:    conn,
+		client:  classifierpb.NewClassifierClient(conn),
+	}, nil
+}
+
+func (c *GRPCClient) Classify(requestData DataRequest) (*[]DataResponseItem, error) {
+	predictions, err := c.classify(requestData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mirror the JSON endpoint: one best label per item.
+	dataResponse := make([]DataResponseItem, 0, len(predictions))
+	for _, prediction := range predictions {
+		if len(prediction) > 0 {
+			dataResponse = append(dataResponse, prediction[0])
+		}
+	}
+
+	return &dataResponse, nil
+}
+
+// ClassifyTopK returns the TopK best labels for every requested item.
+func (c *GRPCClient) ClassifyTopK(requestData DataRequest) (*[][]DataResponseItem, error) {
+	if requestData.TopK < 1 {
+		return nil, fmt.Errorf("top k must be at least 1, got %d", requestData.TopK)
+	}
+
+	predictions, err := c.classify(requestData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &predictions, nil
+}
+
+// ModelInfo asks the service which model it serves.
+func (c *GRPCClient) ModelInfo() (*ModelInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	info, err := c.client.GetModelInfo(ctx, &classifierpb.ModelInfoRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("getting model info: %w", err)
+	}
+
+	return &ModelInfo{
+		Name:      info.GetName(),
+		Version:   info.GetVersion(),
+		Labels:    info.GetLabels(),
+		MaxTokens: int(info.GetMaxTokens()),
+	}, nil
+}
+
+// Healthy reports whether the service is ready to classify.
+func (c *GRPCClient) Healthy() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	resp, err := c.client.Health(ctx, &classifierpb.HealthRequest{})
+	if err != nil {
+		return false, fmt.Errorf("checking health: %w", err)
+	}
+
+	return resp.GetStatus() == classifierpb.HealthResponse_SERVING, nil
+}
+
+// Close releases the underlying connection.
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *GRPCClient) classify(requestData DataRequest) ([][]DataResponseItem, error) {
+	texts := make([]string, 0, len(requestData.Items))
+	for _, item := range requestData.Items {
+		texts = append(texts, item.Text)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	resp, err := c.client.Classify(ctx, &classifierpb.ClassifyRequest{
+		Texts: texts,
+		TopK:  int32(requestData.TopK), //nolint:gosec // top k is a small configured value
+	})
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+
+	if len(resp.GetPredictions()) != len(texts) {
+		return nil, fmt.Errorf(
+			"unexpected prediction count: %d for %d texts",
+			len(resp.GetPredictions()),
+			len(texts),
+		)
+	}
+
+	predictions := make([][]DataResponseItem, 0, len(resp.GetPredictions()))
+	for _, prediction := range resp.GetPredictions() {
+		items := make([]DataResponseItem, 0, len(prediction.GetLabels()))
+		for _, label := range prediction.GetLabels() {
+			items = append(items, DataResponseItem{Label: label.GetLabel(), Score: label.GetScore()})
+		}
+		predictions = append(predictions, items)
+	}
+
+	return predictions, nil
+}
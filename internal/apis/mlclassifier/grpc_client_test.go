@@ -0,0 +1,128 @@
+package mlclassifier_test
+
+import (
+	"strings"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier"
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier/classifierpb"
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier/mlclassifiertest"
+)
+
+func sentimentLabels(text string) []*classifierpb.ScoredLabel {
+	if strings.Contains(text, "crash") {
+		return []*classifierpb.ScoredLabel{
+			{Label: "neutral", Score: 0.1},
+			{Label: "negative", Score: 0.8},
+			{Label: "positive", Score: 0.1},
+		}
+	}
+	return []*classifierpb.ScoredLabel{
+		{Label: "positive", Score: 0.6},
+		{Label: "neutral", Score: 0.3},
+		{Label: "negative", Score: 0.1},
+	}
+}
+
+func newGRPCClient(t *testing.T, server *mlclassifiertest.FakeServer) mlclassifier.API {
+	t.Helper()
+
+	client, err := mlclassifier.NewClientForURL(server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := client.(*mlclassifier.GRPCClient); !ok {
+		t.Fatalf("Expected a gRPC client for %s, got %T", server.URL, client)
+	}
+	return client
+}
+
+func TestGRPCClient_Classify(t *testing.T) {
+	server := mlclassifiertest.NewFakeServer(sentimentLabels)
+	defer server.Close()
+
+	client := newGRPCClient(t, server)
+	resp, err := client.Classify(mlclassifier.DataRequest{
+		Items: []mlclassifier.DataRequestItem{
+			{Text: "markets crash"},
+			{Text: "record highs"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(*resp) != 2 {
+		t.Fatalf("Expected 2 response items, got %d", len(*resp))
+	}
+	if (*resp)[0].Label != "negative" || (*resp)[0].Score != 0.8 {
+		t.Errorf("Unexpected first item: %+v", (*resp)[0])
+	}
+	if (*resp)[1].Label != "positive" {
+		t.Errorf("Unexpected second item: %+v", (*resp)[1])
+	}
+	if server.Requests() != 1 {
+		t.Errorf("Expected a single batched request, got %d", server.Requests())
+	}
+}
+
+func TestGRPCClient_ClassifyTopK(t *testing.T) {
+	server := mlclassifiertest.NewFakeServer(sentimentLabels)
+	defer server.Close()
+
+	client := newGRPCClient(t, server)
+	resp, err := client.ClassifyTopK(mlclassifier.DataRequest{
+		Items: []mlclassifier.DataRequestItem{{Text: "record highs"}},
+		TopK:  2,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(*resp) != 1 || len((*resp)[0]) != 2 {
+		t.Fatalf("Expected 1 item with 2 labels, got %v", *resp)
+	}
+	if (*resp)[0][1].Label != "neutral" {
+		t.Errorf("Unexpected second label: %+v", (*resp)[0][1])
+	}
+}
+
+func TestGRPCClient_ModelInfoAndHealth(t *testing.T) {
+	server := mlclassifiertest.NewFakeServer(sentimentLabels)
+	defer server.Close()
+
+	client, err := mlclassifier.NewGRPCClient(strings.TrimPrefix(server.URL, "grpc://"), false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer client.Close()
+
+	info, err := client.ModelInfo()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Version != "fake-1" {
+		t.Errorf("Expected version fake-1, got %s", info.Version)
+	}
+
+	healthy, err := client.Healthy()
+	if err != nil || !healthy {
+		t.Errorf("Expected healthy server, got %t (%v)", healthy, err)
+	}
+
+	server.SetServing(false)
+	healthy, err = client.Healthy()
+	if err != nil || healthy {
+		t.Errorf("Expected unhealthy server, got %t (%v)", healthy, err)
+	}
+}
+
+func TestNewClientForURL_HTTP(t *testing.T) {
+	client, err := mlclassifier.NewClientForURL("http://localhost:3101")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := client.(*mlclassifier.Client); !ok {
+		t.Errorf("Expected JSON client for http url, got %T", client)
+	}
+}
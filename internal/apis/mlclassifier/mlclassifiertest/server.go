@@ -0,0 +1,121 @@
+// Package mlclassifiertest provides an in-process gRPC classifier for tests.
+package mlclassifiertest
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier/classifierpb"
+)
+
+// LabelFunc returns the scored labels for a text.
+type LabelFunc func(text string) []*classifierpb.ScoredLabel
+
+// FakeServer serves the classifierpb.Classifier protocol on a loopback port.
+type FakeServer struct {
+	classifierpb.UnimplementedClassifierServer
+
+	// URL is the grpc:// address clients should use.
+	URL  string
+	Info *classifierpb.ModelInfo
+
+	labels   LabelFunc
+	server   *grpc.Server
+	mu       sync.Mutex
+	requests int
+	serving  bool
+}
+
+// NewFakeServer starts a fake classifier answering every text with labels(text).
+func NewFakeServer(labels LabelFunc) *FakeServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("mlclassifiertest: failed to listen: " + err.Error())
+	}
+
+	s := &FakeServer{
+		URL:     "grpc://" + lis.Addr().String(),
+		Info:    &classifierpb.ModelInfo{Name: "fake", Version: "fake-1"},
+		labels:  labels,
+		server:  grpc.NewServer(),
+		serving: true,
+	}
+	classifierpb.RegisterClassifierServer(s.server, s)
+
+	go func() {
+		_ = s.server.Serve(lis)
+	}()
+
+	return s
+}
+
+// Close stops the server.
+func (s *FakeServer) Close() {
+	s.server.Stop()
+}
+
+// Requests returns the number of Classify calls served so far.
+func (s *FakeServer) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// SetServing changes the status reported by Health.
+func (s *FakeServer) SetServing(serving bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serving = serving
+}
+
+func (s *FakeServer) Classify(
+	_ context.Context,
+	req *classifierpb.ClassifyRequest,
+) (*classifierpb.ClassifyResponse, error) {
+	s.mu.Lock()
+	s.requests++
+	s.mu.Unlock()
+
+	topK := int(req.GetTopK())
+	if topK < 1 {
+		topK = 1
+	}
+
+	resp := &classifierpb.ClassifyResponse{ModelVersion: s.Info.GetVersion()}
+	for _, text := range req.GetTexts() {
+		labels := s.labels(text)
+		sort.SliceStable(labels, func(i, j int) bool {
+			return labels[i].GetScore() > labels[j].GetScore()
+		})
+		if len(labels) > topK {
+			labels = labels[:topK]
+		}
+		resp.Predictions = append(resp.Predictions, &classifierpb.Prediction{Labels: labels})
+	}
+
+	return resp, nil
+}
+
+func (s *FakeServer) GetModelInfo(
+	_ context.Context,
+	_ *classifierpb.ModelInfoRequest,
+) (*classifierpb.ModelInfo, error) {
+	return s.Info, nil
+}
+
+func (s *FakeServer) Health(
+	_ context.Context,
+	_ *classifierpb.HealthRequest,
+) (*classifierpb.HealthResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := classifierpb.HealthResponse_NOT_SERVING
+	if s.serving {
+		status = classifierpb.HealthResponse_SERVING
+	}
+	return &classifierpb.HealthResponse{Status: status}, nil
+}
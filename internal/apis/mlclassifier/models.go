@@ -8,6 +8,8 @@ type DataRequestItem struct {
 // OuterMap represents the structure of the outer map.
 type DataRequest struct {
 	Items []DataRequestItem `json:"items"`
+	// TopK asks the service for the k best labels per item instead of just one.
+	TopK int `json:"top_k,omitempty"`
 }
 
 type DataResponseItem struct {
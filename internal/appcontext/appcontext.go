@@ -9,6 +9,7 @@ import (
 	"stockseer.ai/blueksy-firehose/internal/config"
 	"stockseer.ai/blueksy-firehose/internal/interfaces"
 	"stockseer.ai/blueksy-firehose/internal/logger"
+	"stockseer.ai/blueksy-firehose/internal/models"
 	"stockseer.ai/blueksy-firehose/internal/repositories"
 )
 
@@ -19,42 +20,108 @@ type AppContext struct {
 	Config      config.AppConfig
 	Log         logger.Logger
 	MongoClient *mongo.Client
-	MQTTClient  interfaces.MqttClient
-	MetricsRepo repositories.Repository
-	MessageRepo repositories.Repository
+	MessageRepo repositories.Repository[models.ProtoMessage]
+
+	// Category and ticker metrics are stored in the same collection.
+	CategoryMetricsRepo repositories.Repository[models.CategoryMetrics]
+	TickerMetricsRepo   repositories.Repository[models.TickerMetrics]
+
+	MessageClient interfaces.MessageClient
+
+	ClassificationStore repositories.ClassificationStore
+	DeadLetterStore     repositories.DeadLetterStore
 }
 
 // NewAppContext creates a new AppContext.
 func NewAppContext(
 	config *config.AppConfig,
 	wssReader bool,
-	mqttClient interfaces.MqttClient,
+	messageClient interfaces.MessageClient,
 ) AppContext {
 	log := logger.NewLogger()
 
-	var metricsRepo repositories.Repository
-	var messageRepo repositories.Repository
+	var messageRepo repositories.Repository[models.ProtoMessage]
+	var categoryMetricsRepo repositories.Repository[models.CategoryMetrics]
+	var tickerMetricsRepo repositories.Repository[models.TickerMetrics]
+	var classificationStore repositories.ClassificationStore
+	var deadLetterStore repositories.DeadLetterStore
 	var client *mongo.Client
 	// Initialize repositories
 	if !wssReader {
 		// Connect to MongoDB
 		clientOptions := options.Client().ApplyURI(config.MongoURI)
-		client, err := mongo.Connect(context.Background(), clientOptions)
+		var err error
+		client, err = mongo.Connect(context.Background(), clientOptions)
 		if err != nil {
 			panic(fmt.Sprintf("Failed to connect to MongoDB: %s", err))
 		}
+		db := config.MongoDatabase
+
+		if config.MongoEnsureSchema {
+			err := repositories.EnsureMongoSchema(
+				context.Background(),
+				client.Database(db),
+				repositories.MongoSchema{
+					Messages:          config.MongoMessagesCollection,
+					Metrics:           config.MongoMetricsCollection,
+					MessageTTL:        config.MongoMessageTTL,
+					MetricsTimeSeries: config.MongoMetricsTimeSeries,
+				},
+			)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to set up the MongoDB schema: %s", err))
+			}
+		}
+
+		messageRepo = repositories.NewMongoMessageRepository(client, db, config.MongoMessagesCollection)
+		categoryMetricsRepo = repositories.NewMongoRepository[models.CategoryMetrics](
+			client,
+			db,
+			config.MongoMetricsCollection,
+		)
+		tickerMetricsRepo = repositories.NewMongoRepository[models.TickerMetrics](
+			client,
+			db,
+			config.MongoMetricsCollection,
+		)
 
-		metricsRepo = repositories.NewMongoRepository(client, "blueskyfh", "metrics")
-		messageRepo = repositories.NewMongoRepository(client, "blueskyfh", "messages")
+		if config.ClassifierCacheEnabled && config.ClassifierCacheMongo {
+			store, err := repositories.NewMongoClassificationStore(
+				client,
+				db,
+				config.MongoClassificationCollection,
+			)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to initialize classification cache store: %s", err))
+			}
+			classificationStore = store
+		}
+
+		if config.DeadLetterSink == models.DeadLetterSinkMongo {
+			store, err := repositories.NewMongoDeadLetterStore(
+				client,
+				db,
+				config.DeadLetterCollection,
+			)
+			if err != nil {
+				panic(fmt.Sprintf("Failed to initialize dead letter store: %s", err))
+			}
+			deadLetterStore = store
+		}
 	}
 
 	return AppContext{
 		Config:      *config,
 		Log:         log,
 		MongoClient: client,
-		MetricsRepo: metricsRepo,
 		MessageRepo: messageRepo,
-		MQTTClient:  mqttClient,
+
+		CategoryMetricsRepo: categoryMetricsRepo,
+		TickerMetricsRepo:   tickerMetricsRepo,
+
+		MessageClient:       messageClient,
+		ClassificationStore: classificationStore,
+		DeadLetterStore:     deadLetterStore,
 	}
 }
 
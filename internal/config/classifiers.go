@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Classifier types understood by the registry in the domain package.
+const (
+	// ClassifierTypeRemote calls a classifier service, over gRPC for grpc:// and
+	// grpcs:// urls and over the JSON /classify endpoint otherwise.
+	ClassifierTypeRemote = "remote"
+	// ClassifierTypeHTTP is the original name of ClassifierTypeRemote.
+	ClassifierTypeHTTP = "http"
+	// ClassifierTypeLexicon scores financial sentiment in process from a word list.
+	ClassifierTypeLexicon = "lexicon"
+	// ClassifierTypeTickers extracts cashtags and company names as tickers.
+	ClassifierTypeTickers = "tickers"
+)
+
+// Circuit breaker defaults used when a fallback is configured without them.
+const (
+	DefaultCircuitBreakerFailures = 5
+	DefaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+// IsRemote reports whether the classifier calls out to a service.
+func (spec ClassifierSpec) IsRemote() bool {
+	return spec.Type == ClassifierTypeRemote || spec.Type == ClassifierTypeHTTP
+}
+
+// Well known output fields. Results for any other field are only stored in the
+// message's generic classifications map.
+const (
+	FieldCategories   = "categories"
+	FieldFinSentiment = "fin_sentiment"
+	FieldTickers      = "tickers"
+)
+
+// ClassifierSpec describes a single classifier entry in the classifiers file.
+type ClassifierSpec struct {
+	Name         string `mapstructure:"name"`
+	Type         string `mapstructure:"type"`
+	URL          string `mapstructure:"url"`
+	Field        string `mapstructure:"field"`
+	ModelVersion string `mapstructure:"model_version"`
+	// TopK is the number of labels requested per text, 0 or 1 keeps only the best.
+	TopK int `mapstructure:"top_k"`
+	// MinScore is the confidence below which a label is reported as unknown.
+	MinScore float64 `mapstructure:"min_score"`
+	// Lexicon is an optional "word,valence" CSV extending the lexicon classifier.
+	Lexicon string `mapstructure:"lexicon"`
+	// Symbols is the "SYMBOL,alias,..." reference file used by the tickers classifier.
+	Symbols string `mapstructure:"symbols"`
+	// Fallback is the classifier type answering while this one is failing.
+	Fallback string `mapstructure:"fallback"`
+	// CircuitBreakerFailures consecutive errors stop calls for CircuitBreakerCooldown.
+	CircuitBreakerFailures int           `mapstructure:"circuit_breaker_failures"`
+	CircuitBreakerCooldown time.Duration `mapstructure:"circuit_breaker_cooldown"`
+	// InputTemplate composes the classified text from the post's fields, it
+	// overrides TEXT_INPUT_TEMPLATE for this classifier.
+	InputTemplate string `mapstructure:"input_template"`
+}
+
+// LoadClassifierSpecs reads the list of classifiers from a YAML/JSON/TOML file.
+//
+//	classifiers:
+//	  - name: text-category
+//	    type: http
+//	    url: http://localhost:3101
+//	    field: categories
+//	    top_k: 3
+//	    min_score: 0.4
+//	    fallback: lexicon
+func LoadClassifierSpecs(path string) ([]ClassifierSpec, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading classifiers file %s: %w", path, err)
+	}
+
+	var file struct {
+		Classifiers []ClassifierSpec `mapstructure:"classifiers"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return nil, fmt.Errorf("decoding classifiers file %s: %w", path, err)
+	}
+
+	for i := range file.Classifiers {
+		spec := &file.Classifiers[i]
+		if spec.Name == "" {
+			return nil, fmt.Errorf("classifier #%d in %s has no name", i, path)
+		}
+		if spec.Type == "" {
+			spec.Type = ClassifierTypeRemote
+		}
+		if spec.Field == "" {
+			spec.Field = spec.Name
+		}
+		spec.applyCircuitBreakerDefaults()
+	}
+
+	return file.Classifiers, nil
+}
+
+// FallbackSpec returns the spec of the classifier answering while this one is failing.
+// It stores its results in the same field and gets the settings of its type, such as
+// the lexicon of a lexicon fallback. Its model version is the fallback's own.
+func (spec ClassifierSpec) FallbackSpec() ClassifierSpec {
+	return ClassifierSpec{
+		Name:    spec.Fallback,
+		Type:    spec.Fallback,
+		Field:   spec.Field,
+		TopK:    spec.TopK,
+		Lexicon: spec.Lexicon,
+		Symbols: spec.Symbols,
+	}
+}
+
+func (spec *ClassifierSpec) applyCircuitBreakerDefaults() {
+	if spec.Fallback == "" {
+		return
+	}
+	if spec.CircuitBreakerFailures == 0 {
+		spec.CircuitBreakerFailures = DefaultCircuitBreakerFailures
+	}
+	if spec.CircuitBreakerCooldown == 0 {
+		spec.CircuitBreakerCooldown = DefaultCircuitBreakerCooldown
+	}
+}
+
+// legacyClassifierSpecs maps the TEXT_*_CLASSIFIER flags onto classifier specs.
+func legacyClassifierSpecs(cfg *AppConfig) []ClassifierSpec {
+	var specs []ClassifierSpec
+
+	if cfg.TextCategoryClassifier {
+		specs = append(specs, ClassifierSpec{
+			Name:  "TextCategoryClassifier",
+			Type:  ClassifierTypeRemote,
+			URL:   cfg.TextCategoryClassifierURL,
+			Field: FieldCategories,
+		})
+	}
+
+	if cfg.TextFinSentimentClassifier {
+		spec := ClassifierSpec{
+			Name:     "TextFinSentimentClassifier",
+			Type:     ClassifierTypeRemote,
+			URL:      cfg.TextFinSentimentClassifierURL,
+			Field:    FieldFinSentiment,
+			Fallback: cfg.TextFinSentimentClassifierFallback,
+		}
+		spec.applyCircuitBreakerDefaults()
+		specs = append(specs, spec)
+	}
+
+	if cfg.TextTickerExtractor {
+		specs = append(specs, ClassifierSpec{
+			Name:    "TextTickerExtractor",
+			Type:    ClassifierTypeTickers,
+			Field:   FieldTickers,
+			Symbols: cfg.TextTickerSymbolsFile,
+		})
+	}
+
+	return specs
+}
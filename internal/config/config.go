@@ -4,6 +4,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,8 +15,6 @@ type AppConfig struct {
 	MQTTBrokerURL              string
 	MQTTUsername               string
 	MQTTPassword               string
-	MQTTMetricsTopic           string
-	MQTTMessagesTopic          string
 	Host                       string
 	ServerPort                 int
 	JetstreamURL               string
@@ -36,6 +35,91 @@ type AppConfig struct {
 
 	TextCategoryClassifierURL     string
 	TextFinSentimentClassifierURL string
+
+	TextFinSentimentClassifierFallback string
+
+	TextTickerExtractor   bool
+	TextTickerSymbolsFile string
+
+	TextURLExtractor   bool
+	TextURLDomainsFile string
+
+	TextKeywordExtractor  bool
+	TextKeywordTopN       int
+	TextKeywordCorpusSize int
+
+	TextNormalization      bool
+	TextNormalizeLinks     string
+	TextNormalizeMentions  string
+	TextNormalizeHTML      bool
+	TextNormalizeEmoji     string
+	TextNormalizeMaxTokens int
+
+	TextInputTemplate string
+
+	ClassifiersConfigFile string
+	Classifiers           []ClassifierSpec
+
+	MetricsConfidenceWeighted bool
+	MetricsTrackedCategories  []string
+
+	ClassifierCacheEnabled bool
+	ClassifierCacheSize    int
+	ClassifierCacheTTL     time.Duration
+	ClassifierCacheMongo   bool
+
+	MQTTCAFile                string
+	MQTTCertFile              string
+	MQTTKeyFile               string
+	MQTTTLSInsecureSkipVerify bool
+	MQTTCleanSession          bool
+	MQTTStoreDir              string
+	MQTTMessagesQoS           int
+	MQTTMessagesRetain        bool
+	MQTTMetricsQoS            int
+	MQTTMetricsRetain         bool
+	MQTTSharedGroup           string
+	MQTTRouteQoS              int
+	MQTTRouteRetain           bool
+
+	MessageBus        string
+	BusMessagesTopic  string
+	BusMetricsTopic   string
+	BusClientID       string
+	BusPayloadFormat  string
+	BusWorkers        int
+	BusQueueSize      int
+	BusDrainTimeout   time.Duration
+	BusSpoolDir       string
+	BusSpoolSegmentMB int
+	BusSpoolMaxMB     int
+	BusRouteTemplates []string
+
+	NATSURL         string
+	NATSStream      string
+	NATSConsumer    string
+	NATSMaxAge      time.Duration
+	KafkaBrokers    []string
+	KafkaGroup      string
+	KafkaAutoCreate bool
+
+	DeadLetterSink        string
+	DeadLetterTopic       string
+	DeadLetterCollection  string
+	DeadLetterMaxAttempts int
+	DeadLetterMaxReplays  int
+
+	MongoBatchSize      int
+	MongoBatchMaxAge    time.Duration
+	MongoWriteQueueSize int
+
+	MongoDatabase                 string
+	MongoMessagesCollection       string
+	MongoMetricsCollection        string
+	MongoClassificationCollection string
+	MongoEnsureSchema             bool
+	MongoMessageTTL               time.Duration
+	MongoMetricsTimeSeries        bool
 }
 
 func (c AppConfig) String() string {
@@ -75,11 +159,227 @@ func (c AppConfig) String() string {
 	sb.WriteString(
 		fmt.Sprintf("    Financial Sentiment Classifier: %t\n", c.TextFinSentimentClassifier),
 	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Ticker Extractor: %t (Symbols: %s)\n",
+			c.TextTickerExtractor,
+			c.TextTickerSymbolsFile,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    URL Extractor: %t (Domains: %s)\n",
+			c.TextURLExtractor,
+			c.TextURLDomainsFile,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Keyword Extractor: %t (Top: %d, Corpus: %d posts)\n",
+			c.TextKeywordExtractor,
+			c.TextKeywordTopN,
+			c.TextKeywordCorpusSize,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Normalization: %t (Links: %s, Mentions: %s, HTML: %t, Emoji: %s, Max Tokens: %d)\n",
+			c.TextNormalization,
+			c.TextNormalizeLinks,
+			c.TextNormalizeMentions,
+			c.TextNormalizeHTML,
+			c.TextNormalizeEmoji,
+			c.TextNormalizeMaxTokens,
+		),
+	)
+	sb.WriteString(fmt.Sprintf("    Input Template: %q\n", c.TextInputTemplate))
+	sb.WriteString(fmt.Sprintf("    Classifiers File: %s\n", c.ClassifiersConfigFile))
+	for _, spec := range c.Classifiers {
+		sb.WriteString(
+			fmt.Sprintf("      %s (%s) -> %s: %s\n", spec.Name, spec.Type, spec.Field, spec.URL),
+		)
+		if spec.Fallback != "" {
+			sb.WriteString(
+				fmt.Sprintf(
+					"        fallback: %s after %d failures for %s\n",
+					spec.Fallback,
+					spec.CircuitBreakerFailures,
+					spec.CircuitBreakerCooldown,
+				),
+			)
+		}
+		if spec.InputTemplate != "" {
+			sb.WriteString(fmt.Sprintf("        input: %q\n", spec.InputTemplate))
+		}
+	}
+
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Cache: %t (Size: %d, TTL: %s, Mongo: %t)\n",
+			c.ClassifierCacheEnabled,
+			c.ClassifierCacheSize,
+			c.ClassifierCacheTTL,
+			c.ClassifierCacheMongo,
+		),
+	)
+
+	sb.WriteString("\n  Mongo:\n")
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Batch Size: %d (Max Age: %s, Write Queue: %d)\n",
+			c.MongoBatchSize,
+			c.MongoBatchMaxAge,
+			c.MongoWriteQueueSize,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Database: %s (Messages: %s, Metrics: %s, Classifications: %s)\n",
+			c.MongoDatabase,
+			c.MongoMessagesCollection,
+			c.MongoMetricsCollection,
+			c.MongoClassificationCollection,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Ensure Schema: %t (Message TTL: %s, Metrics Time-Series: %t)\n",
+			c.MongoEnsureSchema,
+			c.MongoMessageTTL,
+			c.MongoMetricsTimeSeries,
+		),
+	)
+
+	sb.WriteString("\n  Message Bus:\n")
+	sb.WriteString(fmt.Sprintf("    Backend: %s\n", c.MessageBus))
+	sb.WriteString(
+		fmt.Sprintf("    Topics: Messages %s, Metrics %s\n", c.BusMessagesTopic, c.BusMetricsTopic),
+	)
+	sb.WriteString(fmt.Sprintf("    Client ID: %s\n", c.BusClientID))
+	sb.WriteString(fmt.Sprintf("    Payload Format: %s\n", c.BusPayloadFormat))
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Workers: %d, Queue Size: %d, Drain Timeout: %s\n",
+			c.BusWorkers,
+			c.BusQueueSize,
+			c.BusDrainTimeout,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Spool: %s (Segment: %d MB, Max: %d MB)\n",
+			c.BusSpoolDir,
+			c.BusSpoolSegmentMB,
+			c.BusSpoolMaxMB,
+		),
+	)
+	sb.WriteString(fmt.Sprintf("    Routes: %s\n", strings.Join(c.BusRouteTemplates, ",")))
+	sb.WriteString(
+		fmt.Sprintf(
+			"    NATS: %s (Stream: %s, Consumer: %s, Max Age: %s)\n",
+			c.NATSURL,
+			c.NATSStream,
+			c.NATSConsumer,
+			c.NATSMaxAge,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Kafka: %s (Group: %s, Auto Create Topics: %t)\n",
+			strings.Join(c.KafkaBrokers, ","),
+			c.KafkaGroup,
+			c.KafkaAutoCreate,
+		),
+	)
+
+	sb.WriteString("\n  MQTT:\n")
+	sb.WriteString(
+		fmt.Sprintf(
+			"    TLS: CA %q, Cert %q, Insecure Skip Verify: %t\n",
+			c.MQTTCAFile,
+			c.MQTTCertFile,
+			c.MQTTTLSInsecureSkipVerify,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf("    Clean Session: %t (Store: %s)\n", c.MQTTCleanSession, c.MQTTStoreDir),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Messages: QoS %d, Retain: %t\n",
+			c.MQTTMessagesQoS,
+			c.MQTTMessagesRetain,
+		),
+	)
+	sb.WriteString(
+		fmt.Sprintf("    Metrics: QoS %d, Retain: %t\n", c.MQTTMetricsQoS, c.MQTTMetricsRetain),
+	)
+	sb.WriteString(fmt.Sprintf("    Shared Group: %s\n", c.MQTTSharedGroup))
+	sb.WriteString(fmt.Sprintf("    Routes: QoS %d, Retain: %t\n", c.MQTTRouteQoS, c.MQTTRouteRetain))
+
+	sb.WriteString("\n  Dead Letters:\n")
+	sb.WriteString(fmt.Sprintf("    Sink: %s\n", c.DeadLetterSink))
+	sb.WriteString(
+		fmt.Sprintf("    Topic: %s, Collection: %s\n", c.DeadLetterTopic, c.DeadLetterCollection),
+	)
+	sb.WriteString(
+		fmt.Sprintf(
+			"    Max Attempts: %d, Max Replays: %d\n",
+			c.DeadLetterMaxAttempts,
+			c.DeadLetterMaxReplays,
+		),
+	)
+
+	sb.WriteString("\n  Metrics:\n")
+	sb.WriteString(fmt.Sprintf("    Confidence Weighted: %t\n", c.MetricsConfidenceWeighted))
+	sb.WriteString(
+		fmt.Sprintf("    Tracked Categories: %s\n", strings.Join(c.MetricsTrackedCategories, ",")),
+	)
 
 	return sb.String()
 }
 
 func LoadConfig() (*AppConfig, error) {
+	viper.SetDefault("MQTT_CLEAN_SESSION", true)
+	viper.SetDefault("MQTT_METRICS_RETAIN", true)
+	viper.SetDefault("MESSAGE_BUS", "mqtt")
+	viper.SetDefault("BUS_PAYLOAD_FORMAT", "json")
+	viper.SetDefault("BUS_WORKERS", 4)
+	viper.SetDefault("BUS_QUEUE_SIZE", 100)
+	viper.SetDefault("BUS_DRAIN_TIMEOUT", "30s")
+	viper.SetDefault("BUS_SPOOL_SEGMENT_MB", 8)
+	viper.SetDefault("BUS_SPOOL_MAX_MB", 512)
+	viper.SetDefault("NATS_URL", "nats://localhost:4222")
+	viper.SetDefault("NATS_STREAM", "BLUESKYFH")
+	viper.SetDefault("NATS_CONSUMER", "blueskyfh-servers")
+	viper.SetDefault("NATS_MAX_AGE", "168h")
+	viper.SetDefault("KAFKA_BROKERS", "localhost:9092")
+	viper.SetDefault("KAFKA_GROUP", "blueskyfh-servers")
+	viper.SetDefault("KAFKA_AUTO_CREATE_TOPICS", true)
+	viper.SetDefault("DEAD_LETTER_TOPIC", "blueskyfh-dead-letters")
+	viper.SetDefault("DEAD_LETTER_COLLECTION", "dead_letters")
+	viper.SetDefault("DEAD_LETTER_MAX_ATTEMPTS", 3)
+	viper.SetDefault("DEAD_LETTER_MAX_REPLAYS", 3)
+	viper.SetDefault("MONGO_BATCH_SIZE", 100)
+	viper.SetDefault("MONGO_BATCH_MAX_AGE", "500ms")
+	viper.SetDefault("MONGO_WRITE_QUEUE_SIZE", 1000)
+	viper.SetDefault("MONGO_DATABASE", "blueskyfh")
+	viper.SetDefault("MONGO_MESSAGES_COLLECTION", "messages")
+	viper.SetDefault("MONGO_METRICS_COLLECTION", "metrics")
+	viper.SetDefault("MONGO_CLASSIFICATION_COLLECTION", "classification_cache")
+	viper.SetDefault("MONGO_ENSURE_SCHEMA", true)
+	viper.SetDefault("MONGO_MESSAGE_TTL", "0s")
+	viper.SetDefault("MONGO_METRICS_TIMESERIES", true)
+	viper.SetDefault("CLASSIFIER_CACHE_SIZE", 10000)
+	viper.SetDefault("CLASSIFIER_CACHE_TTL", "1h")
+	viper.SetDefault("METRICS_TRACKED_CATEGORIES", "labour,politics,economy,conflict")
+	viper.SetDefault("TEXT_KEYWORD_TOP_N", 5)
+	viper.SetDefault("TEXT_KEYWORD_CORPUS_SIZE", 10000)
+	viper.SetDefault("TEXT_NORMALIZE_LINKS", "replace")
+	viper.SetDefault("TEXT_NORMALIZE_MENTIONS", "replace")
+	viper.SetDefault("TEXT_NORMALIZE_HTML", true)
+	viper.SetDefault("TEXT_NORMALIZE_EMOJI", "collapse")
+
 	// Initialize Viper
 	viper.SetConfigFile(".env") // Set the path to your .env file
 	viper.SetConfigType("env")  // Set the file format (optional, as Viper can infer)
@@ -109,12 +409,114 @@ func LoadConfig() (*AppConfig, error) {
 		TextCategoryClassifierURL:     viper.GetString("TEXT_CATEGORY_CLASSIFIER_URL"),
 		TextFinSentimentClassifierURL: viper.GetString("TEXT_FIN_SENTIMENT_CLASSIFIER_URL"),
 		MQTTEnabled:                   viper.GetBool("MQTT_ENABLED"),
-		MQTTMetricsTopic:              viper.GetString("MQTT_METRICS_TOPIC"),
-		MQTTMessagesTopic:             viper.GetString("MQTT_MESSAGES_TOPIC"),
 		MQTTBrokerURL:                 viper.GetString("MQTT_BROKER_URL"),
 		MQTTUsername:                  viper.GetString("MQTT_USERNAME"),
 		MQTTPassword:                  viper.GetString("MQTT_PASSWORD"),
+		MQTTCAFile:                    viper.GetString("MQTT_CA_FILE"),
+		MQTTCertFile:                  viper.GetString("MQTT_CERT_FILE"),
+		MQTTKeyFile:                   viper.GetString("MQTT_KEY_FILE"),
+		MQTTTLSInsecureSkipVerify:     viper.GetBool("MQTT_TLS_INSECURE_SKIP_VERIFY"),
+		MQTTCleanSession:              viper.GetBool("MQTT_CLEAN_SESSION"),
+		MQTTStoreDir:                  viper.GetString("MQTT_STORE_DIR"),
+		MQTTMessagesQoS:               viper.GetInt("MQTT_MESSAGES_QOS"),
+		MQTTMessagesRetain:            viper.GetBool("MQTT_MESSAGES_RETAIN"),
+		MQTTMetricsQoS:                viper.GetInt("MQTT_METRICS_QOS"),
+		MQTTMetricsRetain:             viper.GetBool("MQTT_METRICS_RETAIN"),
+		MQTTSharedGroup:               viper.GetString("MQTT_SHARED_GROUP"),
+		MQTTRouteQoS:                  viper.GetInt("MQTT_ROUTE_QOS"),
+		MQTTRouteRetain:               viper.GetBool("MQTT_ROUTE_RETAIN"),
+		ClassifiersConfigFile:         viper.GetString("CLASSIFIERS_CONFIG_FILE"),
+		TextFinSentimentClassifierFallback: viper.GetString(
+			"TEXT_FIN_SENTIMENT_CLASSIFIER_FALLBACK",
+		),
+		MetricsConfidenceWeighted: viper.GetBool("METRICS_CONFIDENCE_WEIGHTED"),
+		MetricsTrackedCategories:  splitList(viper.GetString("METRICS_TRACKED_CATEGORIES")),
+		ClassifierCacheEnabled:    viper.GetBool("CLASSIFIER_CACHE_ENABLED"),
+		ClassifierCacheSize:       viper.GetInt("CLASSIFIER_CACHE_SIZE"),
+		ClassifierCacheTTL:        viper.GetDuration("CLASSIFIER_CACHE_TTL"),
+		ClassifierCacheMongo:      viper.GetBool("CLASSIFIER_CACHE_MONGO"),
+		TextTickerExtractor:       viper.GetBool("TEXT_TICKER_EXTRACTOR"),
+		TextTickerSymbolsFile:     viper.GetString("TEXT_TICKER_SYMBOLS_FILE"),
+		TextURLExtractor:          viper.GetBool("TEXT_URL_EXTRACTOR"),
+		TextURLDomainsFile:        viper.GetString("TEXT_URL_DOMAINS_FILE"),
+		TextKeywordExtractor:      viper.GetBool("TEXT_KEYWORD_EXTRACTOR"),
+		TextKeywordTopN:           viper.GetInt("TEXT_KEYWORD_TOP_N"),
+		TextKeywordCorpusSize:     viper.GetInt("TEXT_KEYWORD_CORPUS_SIZE"),
+		TextNormalization:         viper.GetBool("TEXT_NORMALIZATION"),
+		TextNormalizeLinks:        viper.GetString("TEXT_NORMALIZE_LINKS"),
+		TextNormalizeMentions:     viper.GetString("TEXT_NORMALIZE_MENTIONS"),
+		TextNormalizeHTML:         viper.GetBool("TEXT_NORMALIZE_HTML"),
+		TextNormalizeEmoji:        viper.GetString("TEXT_NORMALIZE_EMOJI"),
+		TextNormalizeMaxTokens:    viper.GetInt("TEXT_NORMALIZE_MAX_TOKENS"),
+		TextInputTemplate:         viper.GetString("TEXT_INPUT_TEMPLATE"),
+		MessageBus:                viper.GetString("MESSAGE_BUS"),
+		BusMetricsTopic:           viper.GetString(busKey("BUS_METRICS_TOPIC", "MQTT_METRICS_TOPIC")),
+		BusMessagesTopic:          viper.GetString(busKey("BUS_MESSAGES_TOPIC", "MQTT_MESSAGES_TOPIC")),
+		BusClientID:               viper.GetString(busKey("BUS_CLIENT_ID", "MQTT_CLIENT_ID")),
+		BusPayloadFormat:          viper.GetString(busKey("BUS_PAYLOAD_FORMAT", "MQTT_PAYLOAD_FORMAT")),
+		BusWorkers:                viper.GetInt(busKey("BUS_WORKERS", "MQTT_WORKERS")),
+		BusQueueSize:              viper.GetInt(busKey("BUS_QUEUE_SIZE", "MQTT_QUEUE_SIZE")),
+		BusDrainTimeout:           viper.GetDuration(busKey("BUS_DRAIN_TIMEOUT", "MQTT_DRAIN_TIMEOUT")),
+		BusSpoolDir:               viper.GetString(busKey("BUS_SPOOL_DIR", "MQTT_SPOOL_DIR")),
+		BusSpoolSegmentMB:         viper.GetInt(busKey("BUS_SPOOL_SEGMENT_MB", "MQTT_SPOOL_SEGMENT_MB")),
+		BusSpoolMaxMB:             viper.GetInt(busKey("BUS_SPOOL_MAX_MB", "MQTT_SPOOL_MAX_MB")),
+		BusRouteTemplates:         splitList(viper.GetString(busKey("BUS_ROUTE_TEMPLATES", "MQTT_ROUTE_TEMPLATES"))),
+		NATSURL:                   viper.GetString("NATS_URL"),
+		NATSStream:                viper.GetString("NATS_STREAM"),
+		NATSConsumer:              viper.GetString("NATS_CONSUMER"),
+		NATSMaxAge:                viper.GetDuration("NATS_MAX_AGE"),
+		KafkaBrokers:              splitList(viper.GetString("KAFKA_BROKERS")),
+		KafkaGroup:                viper.GetString("KAFKA_GROUP"),
+		KafkaAutoCreate:           viper.GetBool("KAFKA_AUTO_CREATE_TOPICS"),
+		DeadLetterSink:            viper.GetString("DEAD_LETTER_SINK"),
+		DeadLetterTopic:           viper.GetString("DEAD_LETTER_TOPIC"),
+		DeadLetterCollection:      viper.GetString("DEAD_LETTER_COLLECTION"),
+		DeadLetterMaxAttempts:     viper.GetInt("DEAD_LETTER_MAX_ATTEMPTS"),
+		DeadLetterMaxReplays:      viper.GetInt("DEAD_LETTER_MAX_REPLAYS"),
+		MongoBatchSize:            viper.GetInt("MONGO_BATCH_SIZE"),
+		MongoBatchMaxAge:          viper.GetDuration("MONGO_BATCH_MAX_AGE"),
+		MongoWriteQueueSize:       viper.GetInt("MONGO_WRITE_QUEUE_SIZE"),
+
+		MongoDatabase:                 viper.GetString("MONGO_DATABASE"),
+		MongoMessagesCollection:       viper.GetString("MONGO_MESSAGES_COLLECTION"),
+		MongoMetricsCollection:        viper.GetString("MONGO_METRICS_COLLECTION"),
+		MongoClassificationCollection: viper.GetString("MONGO_CLASSIFICATION_COLLECTION"),
+		MongoEnsureSchema:             viper.GetBool("MONGO_ENSURE_SCHEMA"),
+		MongoMessageTTL:               viper.GetDuration("MONGO_MESSAGE_TTL"),
+		MongoMetricsTimeSeries:        viper.GetBool("MONGO_METRICS_TIMESERIES"),
+	}
+
+	// Classifiers come from a dedicated file when one is configured, otherwise we
+	// fall back to the legacy TEXT_* flags so existing .env files keep working.
+	if cfg.ClassifiersConfigFile != "" {
+		specs, err := LoadClassifierSpecs(cfg.ClassifiersConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Classifiers = specs
+	} else {
+		cfg.Classifiers = legacyClassifierSpecs(cfg)
 	}
 
 	return cfg, nil
 }
+
+// busKey returns the bus-neutral key, or its MQTT_* alias when only the alias is set,
+// so .env files written before other buses were supported keep working.
+func busKey(key, alias string) string {
+	if !viper.InConfig(key) && viper.InConfig(alias) {
+		return alias
+	}
+	return key
+}
+
+// splitList splits a comma separated value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned while a classifier is skipped after repeated failures.
+var ErrCircuitOpen = errors.New("classifier circuit is open")
+
+// circuitBreakerClassifier stops calling a failing classifier for a cooldown period
+// after a number of consecutive failures. While the circuit is open, or when a call
+// fails, the optional fallback classifier answers instead.
+type circuitBreakerClassifier struct {
+	Classifier
+	fallback Classifier
+	failures int
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu          sync.Mutex
+	consecutive int
+	openUntil   time.Time
+}
+
+// NewCircuitBreakerClassifier wraps classifier with a circuit breaker that opens after
+// failures consecutive errors for cooldown. fallback may be nil.
+func NewCircuitBreakerClassifier(
+	classifier Classifier,
+	fallback Classifier,
+	failures int,
+	cooldown time.Duration,
+) Classifier {
+	return &circuitBreakerClassifier{
+		Classifier: classifier,
+		fallback:   fallback,
+		failures:   failures,
+		cooldown:   cooldown,
+		now:        time.Now,
+	}
+}
+
+func (c *circuitBreakerClassifier) Classify(text string) (*ClassificationResult, error) {
+	if c.isOpen() {
+		return c.useFallback(text, ErrCircuitOpen)
+	}
+
+	result, err := c.Classifier.Classify(text)
+	c.record(err)
+	if err != nil {
+		return c.useFallback(text, err)
+	}
+	return result, nil
+}
+
+// ModelVersion returns the version the wrapped classifier reports for its model, empty
+// when it reports none.
+func (c *circuitBreakerClassifier) ModelVersion() string {
+	if versioner, ok := c.Classifier.(modelVersioner); ok {
+		return versioner.ModelVersion()
+	}
+	return ""
+}
+
+// Open reports whether calls are currently being skipped.
+func (c *circuitBreakerClassifier) Open() bool {
+	return c.isOpen()
+}
+
+func (c *circuitBreakerClassifier) isOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now().Before(c.openUntil)
+}
+
+func (c *circuitBreakerClassifier) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		c.consecutive = 0
+		return
+	}
+
+	c.consecutive++
+	if c.consecutive >= c.failures {
+		// Half open once the cooldown passes: the next call is let through and
+		// a single failure re-opens the circuit.
+		c.consecutive = c.failures - 1
+		c.openUntil = c.now().Add(c.cooldown)
+	}
+}
+
+func (c *circuitBreakerClassifier) useFallback(text string, cause error) (*ClassificationResult, error) {
+	if c.fallback == nil {
+		return nil, cause
+	}
+
+	result, err := c.fallback.Classify(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w (fallback %s: %w)", cause, c.fallback.Name(), err)
+	}
+	return result, nil
+}
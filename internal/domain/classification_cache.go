@@ -0,0 +1,230 @@
+package domain
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/repositories"
+)
+
+// CacheStats is a snapshot of the classification cache counters.
+type CacheStats struct {
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	StoreErrors uint64
+	Size        int
+}
+
+// HitRate returns the share of lookups served from the cache.
+func (cs CacheStats) HitRate() float64 {
+	total := cs.Hits + cs.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(cs.Hits) / float64(total)
+}
+
+type cacheEntry struct {
+	key       string
+	result    ClassificationResult
+	expiresAt time.Time
+}
+
+// ClassificationCache is an LRU of classifier results with a TTL, optionally
+// backed by a shared store that is consulted on local misses.
+type ClassificationCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List
+	store   repositories.ClassificationStore
+	now     func() time.Time
+
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	storeErrors uint64
+}
+
+// NewClassificationCache creates a cache holding at most size entries for ttl.
+// store may be nil to keep the cache in memory only.
+func NewClassificationCache(
+	size int,
+	ttl time.Duration,
+	store repositories.ClassificationStore,
+) *ClassificationCache {
+	return &ClassificationCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		store:   store,
+		now:     time.Now,
+	}
+}
+
+// CacheKey hashes the normalized text together with the classifier and model version
+// so that a model upgrade never serves stale predictions.
+func CacheKey(classifier, modelVersion, text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+
+	h := sha256.New()
+	h.Write([]byte(classifier))
+	h.Write([]byte{0})
+	h.Write([]byte(modelVersion))
+	h.Write([]byte{0})
+	h.Write([]byte(normalized))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns a copy of the cached result for key.
+func (c *ClassificationCache) Get(key string) (*ClassificationResult, bool) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if c.now().Before(entry.expiresAt) {
+			c.order.MoveToFront(elem)
+			c.hits++
+			result := entry.result.clone()
+			c.mu.Unlock()
+			return result, true
+		}
+		c.removeElement(elem)
+	}
+	c.mu.Unlock()
+
+	if c.store != nil {
+		stored, ok, err := c.store.Get(key)
+		if err != nil {
+			c.mu.Lock()
+			c.storeErrors++
+			c.mu.Unlock()
+		}
+		if ok {
+			result := resultFromProto(stored)
+			c.add(key, result)
+			c.mu.Lock()
+			c.hits++
+			c.mu.Unlock()
+			return result.clone(), true
+		}
+	}
+
+	c.mu.Lock()
+	c.misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// Set caches result under key, evicting the least recently used entry when full.
+func (c *ClassificationCache) Set(key string, result *ClassificationResult) {
+	c.add(key, result)
+
+	if c.store != nil {
+		if err := c.store.Set(key, result.toProto(), c.ttl); err != nil {
+			c.mu.Lock()
+			c.storeErrors++
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Stats returns the current cache counters.
+func (c *ClassificationCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:        c.hits,
+		Misses:      c.misses,
+		Evictions:   c.evictions,
+		StoreErrors: c.storeErrors,
+		Size:        c.order.Len(),
+	}
+}
+
+func (c *ClassificationCache) add(key string, result *ClassificationResult) {
+	// Latency belongs to the original call, a cache hit reports its own.
+	cached := result.clone()
+	cached.Latency = 0
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.result = *cached
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	elem := c.order.PushFront(&cacheEntry{key: key, result: *cached, expiresAt: expiresAt})
+	c.entries[key] = elem
+
+	for c.size > 0 && c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+		c.evictions++
+	}
+}
+
+func (c *ClassificationCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
+
+// modelVersioner is a classifier that knows the version of its model, which may
+// change while it runs.
+type modelVersioner interface {
+	ModelVersion() string
+}
+
+// cachedClassifier serves repeated texts from the cache instead of the wrapped classifier.
+type cachedClassifier struct {
+	Classifier
+	modelVersion string
+	cache        *ClassificationCache
+}
+
+// NewCachedClassifier wraps classifier with cache. Results are cached under the
+// version the classifier reports for its model, modelVersion when it reports none, so
+// results of an earlier model are not served after an upgrade. Results of another
+// classifier, such as a circuit breaker's fallback, are not cached.
+func NewCachedClassifier(
+	classifier Classifier,
+	modelVersion string,
+	cache *ClassificationCache,
+) Classifier {
+	return &cachedClassifier{Classifier: classifier, modelVersion: modelVersion, cache: cache}
+}
+
+func (c *cachedClassifier) Classify(text string) (*ClassificationResult, error) {
+	modelVersion := c.modelVersion
+	if versioner, ok := c.Classifier.(modelVersioner); ok {
+		if version := versioner.ModelVersion(); version != "" {
+			modelVersion = version
+		}
+	}
+
+	key := CacheKey(c.Name(), modelVersion, text)
+	if result, ok := c.cache.Get(key); ok {
+		return result, nil
+	}
+
+	result, err := c.Classifier.Classify(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Classifier == c.Name() {
+		c.cache.Set(key, result)
+	}
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+type countingClassifier struct {
+	calls int
+}
+
+func (c *countingClassifier) Name() string {
+	return "counting"
+}
+
+func (c *countingClassifier) Classify(text string) (*ClassificationResult, error) {
+	c.calls++
+	return &ClassificationResult{Classifier: c.Name(), Labels: []LabelScore{{Label: "finance", Score: 0.9}}}, nil
+}
+
+func TestCacheKey_Normalization(t *testing.T) {
+	a := CacheKey("category", "v1", "Stocks  are UP\ttoday ")
+	b := CacheKey("category", "v1", "stocks are up today")
+	if a != b {
+		t.Errorf("Expected normalized texts to share a key")
+	}
+
+	if a == CacheKey("category", "v2", "stocks are up today") {
+		t.Errorf("Expected model version to change the key")
+	}
+	if a == CacheKey("sentiment", "v1", "stocks are up today") {
+		t.Errorf("Expected classifier name to change the key")
+	}
+}
+
+func TestCachedClassifier(t *testing.T) {
+	inner := &countingClassifier{}
+	cache := NewClassificationCache(10, time.Hour, nil)
+	classifier := NewCachedClassifier(inner, "v1", cache)
+
+	for _, text := range []string{"Buy the dip", "buy the  DIP", "sell everything"} {
+		if _, err := classifier.Classify(text); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if inner.calls != 2 {
+		t.Errorf("Expected 2 classifier calls, got %d", inner.calls)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Size != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if rate := stats.HitRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("Unexpected hit rate: %f", rate)
+	}
+}
+
+// versionedClassifier reports the version of its model.
+type versionedClassifier struct {
+	countingClassifier
+	version string
+}
+
+func (c *versionedClassifier) ModelVersion() string {
+	return c.version
+}
+
+func TestCachedClassifier_ReportedModelVersion(t *testing.T) {
+	inner := &versionedClassifier{version: "v1"}
+	classifier := NewCachedClassifier(inner, "", NewClassificationCache(10, time.Hour, nil))
+
+	for _, version := range []string{"v1", "v1", "v2"} {
+		inner.version = version
+		if _, err := classifier.Classify("Buy the dip"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if inner.calls != 2 {
+		t.Errorf("Expected the upgraded model to be called again, got %d calls", inner.calls)
+	}
+}
+
+func TestClassificationCache_EvictionAndTTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewClassificationCache(2, time.Minute, nil)
+	cache.now = func() time.Time { return now }
+
+	result := &ClassificationResult{Labels: []LabelScore{{Label: "a", Score: 1}}}
+	cache.Set("a", result)
+	cache.Set("b", result)
+	cache.Get("a") // a is now the most recently used entry
+	cache.Set("c", result)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Errorf("Expected recently used entry to be kept")
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 {
+		t.Errorf("Expected 1 eviction, got %d", stats.Evictions)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get("c"); ok {
+		t.Errorf("Expected expired entry to be dropped")
+	}
+}
+
+func TestCachedClassifier_OutsideCircuitBreaker(t *testing.T) {
+	inner := &countingClassifier{}
+	fallback := &stubClassifier{name: "lexicon", labels: []LabelScore{{Label: "neutral", Score: 0.5}}}
+	breaker := NewCircuitBreakerClassifier(inner, fallback, 1, time.Hour).(*circuitBreakerClassifier)
+	classifier := NewCachedClassifier(breaker, "v1", NewClassificationCache(10, time.Hour, nil))
+
+	if _, err := classifier.Classify("Buy the dip"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Open the circuit: the cached text is still served by the classifier's result,
+	// others by the fallback, whose results are not cached.
+	breaker.openUntil = time.Now().Add(time.Hour)
+	if result, err := classifier.Classify("Buy the dip"); err != nil || result.TopLabel() != "finance" {
+		t.Errorf("Expected the cached result while the circuit is open, got %v (%v)", result, err)
+	}
+	for range 2 {
+		if result, err := classifier.Classify("sell everything"); err != nil || result.TopLabel() != "neutral" {
+			t.Errorf("Expected the fallback result, got %v (%v)", result, err)
+		}
+	}
+	if len(fallback.texts) != 2 {
+		t.Errorf("Expected fallback results not to be cached, got %d fallback calls", len(fallback.texts))
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected 1 classifier call, got %d", inner.calls)
+	}
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"strings"
+	"text/template"
+
+	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/templates"
+)
+
+// DefaultInputTemplate classifies the post text only.
+const DefaultInputTemplate = "{{.Text}}"
+
+// ClassificationInput holds the parts of a post an input template can use.
+type ClassificationInput struct {
+	Text            string
+	AltText         string
+	QuotedText      string
+	LinkTitle       string
+	LinkDescription string
+}
+
+// NewClassificationInput gathers the text, image alt texts, quoted post text and
+// link card of message.
+func NewClassificationInput(message *models.ProtoMessage) ClassificationInput {
+	record := message.GetCommit().GetRecord()
+	if record == nil {
+		return ClassificationInput{}
+	}
+
+	input := ClassificationInput{Text: record.Text}
+
+	var altTexts []string
+	addAlt := func(alt string) {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			altTexts = append(altTexts, alt)
+		}
+	}
+
+	external := record.External
+	if embed := record.Embed; embed != nil {
+		for _, image := range embed.Images {
+			addAlt(image.GetAlt())
+		}
+		addAlt(embed.GetAlt())
+		addAlt(embed.GetAlttext())
+		if media := embed.Media; media != nil {
+			for _, image := range media.Images {
+				addAlt(image.GetAlt())
+			}
+			addAlt(media.GetAlt())
+			if media.External != nil {
+				external = media.External
+			}
+		}
+		if embed.External != nil {
+			external = embed.External
+		}
+		input.QuotedText = embed.GetRecord().GetRecord().GetText()
+	}
+	input.AltText = strings.Join(altTexts, ". ")
+
+	if external != nil {
+		input.LinkTitle = external.GetTitle()
+		input.LinkDescription = external.GetDescription()
+	}
+
+	return input
+}
+
+// normalize returns the input with every field normalized. Only the post text has
+// facets, so the other fields skip the link and mention handling. A bridged post is
+// classified on its full original HTML instead of the shortened post text.
+func (ci ClassificationInput) normalize(tn *TextNormalizer, message *models.ProtoMessage) ClassificationInput {
+	text := tn.clean(ci.Text, message)
+	if original := message.GetCommit().GetRecord().GetBridgyOriginalText(); original != "" {
+		if !tn.html {
+			original = unescapeHTML(original)
+		}
+		text = tn.clean(original, nil)
+	}
+
+	return ClassificationInput{
+		Text:            text,
+		AltText:         tn.clean(ci.AltText, nil),
+		QuotedText:      tn.clean(ci.QuotedText, nil),
+		LinkTitle:       tn.clean(ci.LinkTitle, nil),
+		LinkDescription: tn.clean(ci.LinkDescription, nil),
+	}
+}
+
+// InputComposer renders a ClassificationInput into the text sent to a classifier.
+type InputComposer struct {
+	source string
+	tmpl   *template.Template
+}
+
+// NewInputComposer parses a text/template over the ClassificationInput fields, e.g.
+//
+//	{{.Text}} {{with .LinkTitle}}{{.}}. {{end}}{{.LinkDescription}}
+//
+// An empty source uses DefaultInputTemplate.
+func NewInputComposer(source string) (*InputComposer, error) {
+	if source == "" {
+		source = DefaultInputTemplate
+	}
+
+	tmpl, err := templates.Parse("input", source, ClassificationInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &InputComposer{source: source, tmpl: tmpl}, nil
+}
+
+// Source returns the template the composer was created from.
+func (ic *InputComposer) Source() string {
+	return ic.source
+}
+
+// Compose renders input, collapsing the whitespace left by empty fields. The default
+// template returns the post text untouched.
+func (ic *InputComposer) Compose(input ClassificationInput) (string, error) {
+	if ic.source == DefaultInputTemplate {
+		return input.Text, nil
+	}
+
+	var sb strings.Builder
+	if err := ic.tmpl.Execute(&sb, input); err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(sb.String()), " "), nil
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func embedMessage(text string, embed *models.Embed) *models.ProtoMessage {
+	return &models.ProtoMessage{
+		Commit: &models.Commit{Record: &models.Record{Text: text, Embed: embed}},
+	}
+}
+
+func TestNewClassificationInput(t *testing.T) {
+	message := embedMessage("Look at this", &models.Embed{
+		Images: []*models.EmbedImage{
+			{Alt: strPtr("Chart of the S&P 500 falling")},
+			{Alt: strPtr("  ")},
+		},
+		Media: &models.EmbedMedia{
+			External: &models.EmbedExternal{
+				Title:       strPtr("Stocks slide"),
+				Description: strPtr("Markets fell on rate fears"),
+			},
+		},
+		Record: &models.EmbedRecord{Record: &models.Record{Text: "Quoted take"}},
+	})
+
+	input := NewClassificationInput(message)
+
+	expected := ClassificationInput{
+		Text:            "Look at this",
+		AltText:         "Chart of the S&P 500 falling",
+		QuotedText:      "Quoted take",
+		LinkTitle:       "Stocks slide",
+		LinkDescription: "Markets fell on rate fears",
+	}
+	if input != expected {
+		t.Errorf("Expected %+v, got %+v", expected, input)
+	}
+}
+
+func TestInputComposer_Compose(t *testing.T) {
+	input := ClassificationInput{Text: "  Big   news ", LinkTitle: "Fed cuts rates"}
+
+	testCases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "Default", source: "", expected: "  Big   news "},
+		{
+			name:     "Link card",
+			source:   "{{.Text}} {{with .LinkTitle}}{{.}}.{{end}} {{.LinkDescription}}",
+			expected: "Big news Fed cuts rates.",
+		},
+		{name: "Alt text only", source: "{{.AltText}}", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			composer, err := NewInputComposer(tc.source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			text, err := composer.Compose(input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if text != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, text)
+			}
+		})
+	}
+}
+
+func TestNewInputComposer_Invalid(t *testing.T) {
+	for _, source := range []string{"{{.Text", "{{.Body}}"} {
+		if _, err := NewInputComposer(source); err == nil {
+			t.Errorf("Expected an error for %q", source)
+		}
+	}
+}
+
+func TestTextProcessorFactory_ProcessMessage(t *testing.T) {
+	cfg := &config.AppConfig{
+		TextInputTemplate: "{{.Text}} {{.AltText}}",
+		Classifiers: []config.ClassifierSpec{
+			{Name: "sentiment", Type: config.ClassifierTypeLexicon, Field: "sentiment"},
+			{
+				Name:          "links",
+				Type:          config.ClassifierTypeLexicon,
+				Field:         "links",
+				InputTemplate: "{{.LinkTitle}}",
+			},
+		},
+	}
+	tpf, err := InitProcessors(cfg, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stubs := make([]*stubClassifier, len(tpf.processors))
+	for i, tp := range tpf.processors {
+		stubs[i] = &stubClassifier{name: tp.Description, labels: []LabelScore{{Label: "x", Score: 1}}}
+		tpf.processors[i].Classifier = stubs[i]
+	}
+
+	message := embedMessage("", &models.Embed{
+		Images:   []*models.EmbedImage{{Alt: strPtr("A rising chart")}},
+		External: &models.EmbedExternal{Title: strPtr("Earnings beat")},
+	})
+	results, err := tpf.ProcessMessage(message)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %v", results)
+	}
+	if len(stubs[0].texts) != 1 || stubs[0].texts[0] != "A rising chart" {
+		t.Errorf("Expected the default template input, got %q", stubs[0].texts)
+	}
+	if len(stubs[1].texts) != 1 || stubs[1].texts[0] != "Earnings beat" {
+		t.Errorf("Expected the classifier's own template input, got %q", stubs[1].texts)
+	}
+}
+
+func TestTextProcessorFactory_ProcessMessageWith(t *testing.T) {
+	tpf := NewTextProcessorFactory()
+	working := &stubClassifier{name: "sentiment", labels: []LabelScore{{Label: "x", Score: 1}}}
+	failing := &stubClassifier{name: "toxicity", err: errors.New("boom")}
+	tpf.AddProcessor("sentiment", 0, working)
+	tpf.AddProcessor("toxicity", 0, failing)
+
+	retry := func(fn func() error) (int, error) {
+		var err error
+		for attempt := 1; ; attempt++ {
+			if err = fn(); err == nil || attempt == 3 {
+				return attempt, err
+			}
+		}
+	}
+	results, attempts, err := tpf.ProcessMessageWith(embedMessage("Stocks rally", nil), retry)
+	if err == nil {
+		t.Error("Expected the error of the failing classifier")
+	}
+	if len(results) != 1 || attempts != 3 {
+		t.Errorf("Expected 1 result after 3 attempts, got %v after %d", results, attempts)
+	}
+	if len(working.texts) != 1 {
+		t.Errorf("Expected the working classifier to run once, got %d runs", len(working.texts))
+	}
+}
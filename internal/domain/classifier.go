@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// LabelScore is a single label predicted by a classifier and its confidence.
+type LabelScore struct {
+	Label string
+	Score float64
+}
+
+// ClassificationResult is the structured output of a classifier for one text.
+type ClassificationResult struct {
+	Classifier   string
+	Field        string
+	Labels       []LabelScore
+	ModelVersion string
+	Latency      time.Duration
+}
+
+// UnknownLabel replaces predictions whose confidence is below the classifier's threshold.
+const UnknownLabel = "unknown"
+
+// TopLabel returns the highest ranked label or an empty string.
+func (cr *ClassificationResult) TopLabel() string {
+	if cr == nil || len(cr.Labels) == 0 {
+		return ""
+	}
+	return cr.Labels[0].Label
+}
+
+// TopScore returns the confidence of the highest ranked label or 0.
+func (cr *ClassificationResult) TopScore() float64 {
+	if cr == nil || len(cr.Labels) == 0 {
+		return 0
+	}
+	return cr.Labels[0].Score
+}
+
+// clone returns a deep copy so cached results cannot be mutated by callers.
+func (cr *ClassificationResult) clone() *ClassificationResult {
+	cloned := *cr
+	cloned.Labels = append([]LabelScore(nil), cr.Labels...)
+	return &cloned
+}
+
+// toProto converts the result into its stored form.
+func (cr *ClassificationResult) toProto() *models.Classification {
+	labels := make([]string, 0, len(cr.Labels))
+	scores := make([]float64, 0, len(cr.Labels))
+	for _, ls := range cr.Labels {
+		labels = append(labels, ls.Label)
+		scores = append(scores, ls.Score)
+	}
+
+	return &models.Classification{
+		Classifier:   cr.Classifier,
+		Labels:       labels,
+		Scores:       scores,
+		ModelVersion: cr.ModelVersion,
+		LatencyUs:    cr.Latency.Microseconds(),
+	}
+}
+
+// resultFromProto is the inverse of toProto.
+func resultFromProto(classification *models.Classification) *ClassificationResult {
+	labels := make([]LabelScore, 0, len(classification.Labels))
+	for i, label := range classification.Labels {
+		ls := LabelScore{Label: label}
+		if i < len(classification.Scores) {
+			ls.Score = classification.Scores[i]
+		}
+		labels = append(labels, ls)
+	}
+
+	return &ClassificationResult{
+		Classifier:   classification.Classifier,
+		Labels:       labels,
+		ModelVersion: classification.ModelVersion,
+		Latency:      time.Duration(classification.LatencyUs) * time.Microsecond,
+	}
+}
+
+// applyThreshold drops labels below minScore. When nothing is left the result
+// keeps a single unknown label carrying the best score that was seen.
+func (cr *ClassificationResult) applyThreshold(minScore float64) {
+	if minScore <= 0 || len(cr.Labels) == 0 {
+		return
+	}
+
+	kept := cr.Labels[:0:0]
+	for _, ls := range cr.Labels {
+		if ls.Score >= minScore {
+			kept = append(kept, ls)
+		}
+	}
+
+	if len(kept) == 0 {
+		kept = append(kept, LabelScore{Label: UnknownLabel, Score: cr.TopScore()})
+	}
+	cr.Labels = kept
+}
+
+// Classifier turns a piece of text into labelled results.
+type Classifier interface {
+	Name() string
+	Classify(text string) (*ClassificationResult, error)
+}
+
+// ClassifierConstructor builds a Classifier from its configuration entry.
+type ClassifierConstructor func(spec config.ClassifierSpec) (Classifier, error)
+
+var (
+	classifierTypesMu sync.RWMutex
+	classifierTypes   = map[string]ClassifierConstructor{
+		config.ClassifierTypeRemote:  newRemoteClassifier,
+		config.ClassifierTypeHTTP:    newRemoteClassifier,
+		config.ClassifierTypeLexicon: newLexiconClassifier,
+		config.ClassifierTypeTickers: newTickerClassifier,
+	}
+)
+
+// RegisterClassifierType makes a classifier implementation available to the
+// classifiers file under the given type name.
+func RegisterClassifierType(typ string, ctor ClassifierConstructor) {
+	classifierTypesMu.Lock()
+	defer classifierTypesMu.Unlock()
+	classifierTypes[typ] = ctor
+}
+
+// NewClassifier creates the classifier described by spec.
+func NewClassifier(spec config.ClassifierSpec) (Classifier, error) {
+	classifierTypesMu.RLock()
+	ctor, ok := classifierTypes[spec.Type]
+	classifierTypesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("classifier %s: unknown type %q", spec.Name, spec.Type)
+	}
+	return ctor(spec)
+}
+
+var nonWordRegex = regexp.MustCompile(`[\W_]+`)
+
+// fieldSetters copy results for well known fields onto the typed message fields.
+var fieldSetters = map[string]func(message *models.ProtoMessage, result *ClassificationResult){
+	config.FieldCategories: func(message *models.ProtoMessage, result *ClassificationResult) {
+		// Labels such as "politics, government" become one category per word.
+		var categories []string
+		seen := make(map[string]bool)
+		for _, ls := range result.Labels {
+			cleaned := nonWordRegex.ReplaceAllString(ls.Label, " ")
+			for _, category := range strings.Fields(cleaned) {
+				if !seen[category] {
+					seen[category] = true
+					categories = append(categories, category)
+				}
+			}
+		}
+		message.Categories = categories
+	},
+	config.FieldFinSentiment: func(message *models.ProtoMessage, result *ClassificationResult) {
+		sentiment := result.TopLabel()
+		message.FinSentiment = &sentiment
+	},
+	config.FieldTickers: func(message *models.ProtoMessage, result *ClassificationResult) {
+		var tickers []string
+		for _, ls := range result.Labels {
+			if ls.Label != UnknownLabel {
+				tickers = append(tickers, ls.Label)
+			}
+		}
+		message.Tickers = tickers
+	},
+}
+
+// ApplyClassifications stores results on the message. Every result is kept in
+// the generic classifications map keyed by its output field, and well known
+// fields are additionally mirrored onto their dedicated message fields.
+func ApplyClassifications(message *models.ProtoMessage, results []*ClassificationResult) {
+	if message.Classifications == nil {
+		message.Classifications = make(map[string]*models.Classification)
+	}
+
+	for _, result := range results {
+		message.Classifications[result.Field] = result.toProto()
+
+		if setter, ok := fieldSetters[result.Field]; ok {
+			setter(message, result)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier"
+	"stockseer.ai/blueksy-firehose/internal/config"
+)
+
+// remoteClassifier calls one of the classifier services. The transport is chosen
+// from the URL scheme, see mlclassifier.NewClientForURL.
+type remoteClassifier struct {
+	spec   config.ClassifierSpec
+	client mlclassifier.API
+
+	// Model version reported by the service when none is configured, asked again in
+	// the background every modelInfoRefresh so an upgraded model is noticed.
+	mu           sync.Mutex
+	checkedAt    time.Time
+	refreshing   bool
+	modelVersion string
+}
+
+// modelInfoRefresh is how long the model version reported by a service is used
+// before it is asked again.
+var modelInfoRefresh = time.Minute
+
+func newRemoteClassifier(spec config.ClassifierSpec) (Classifier, error) {
+	if spec.URL == "" {
+		return nil, fmt.Errorf("classifier %s: url is required", spec.Name)
+	}
+
+	client, err := mlclassifier.NewClientForURL(spec.URL)
+	if err != nil {
+		return nil, fmt.Errorf("classifier %s: %w", spec.Name, err)
+	}
+
+	c := &remoteClassifier{spec: spec, client: client}
+	if provider, ok := client.(mlclassifier.ModelInfoProvider); ok && spec.ModelVersion == "" {
+		// Asked once up front, so the first results already carry the version.
+		c.refreshing = true
+		c.refreshVersion(provider)
+	}
+	return c, nil
+}
+
+func (c *remoteClassifier) Name() string {
+	return c.spec.Name
+}
+
+func (c *remoteClassifier) Classify(text string) (*ClassificationResult, error) {
+	data := mlclassifier.DataRequest{
+		Items: []mlclassifier.DataRequestItem{
+			{Text: text},
+		},
+	}
+
+	var items []mlclassifier.DataResponseItem
+	if c.spec.TopK > 1 {
+		data.TopK = c.spec.TopK
+		resp, err := c.client.ClassifyTopK(data)
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil && len(*resp) > 0 {
+			items = (*resp)[0]
+		}
+	} else {
+		resp, err := c.client.Classify(data)
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil {
+			items = *resp
+		}
+	}
+
+	if len(items) == 0 || items[0].Label == "" {
+		return nil, errors.New("response is nil in API call")
+	}
+
+	labels := make([]LabelScore, 0, len(items))
+	for _, item := range items {
+		labels = append(labels, LabelScore{Label: item.Label, Score: item.Score})
+	}
+
+	return &ClassificationResult{
+		Classifier:   c.spec.Name,
+		Field:        c.spec.Field,
+		Labels:       labels,
+		ModelVersion: c.version(),
+	}, nil
+}
+
+// ModelVersion returns the version of the model classifying the texts, see version.
+func (c *remoteClassifier) ModelVersion() string {
+	return c.version()
+}
+
+// version returns the configured model version, falling back to the version the
+// service last described its model with, when it can. A stale version is refreshed
+// in the background, so a slow service never holds up the classification.
+func (c *remoteClassifier) version() string {
+	if c.spec.ModelVersion != "" {
+		return c.spec.ModelVersion
+	}
+	provider, ok := c.client.(mlclassifier.ModelInfoProvider)
+	if !ok {
+		return ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.refreshing && (c.checkedAt.IsZero() || time.Since(c.checkedAt) >= modelInfoRefresh) {
+		c.refreshing = true
+		go c.refreshVersion(provider)
+	}
+	return c.modelVersion
+}
+
+// refreshVersion asks the service which model it serves, keeping the last known
+// version when it cannot tell.
+func (c *remoteClassifier) refreshVersion(provider mlclassifier.ModelInfoProvider) {
+	info, err := provider.ModelInfo()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err == nil {
+		c.modelVersion = info.Version
+	}
+	c.checkedAt = time.Now()
+	c.refreshing = false
+}
@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// stopwords per ISO 639-1 language code. Posts in other languages keep every word.
+var stopwords = map[string]map[string]bool{
+	"en": wordSet(`a about above after again against all also am an and any are as at be because been before
+		being below between both but by can could did do does doing down during each few for from further get
+		got had has have having he her here hers herself him himself his how i if in into is it its itself
+		just like me more most my myself no nor not now of off on once only or other our ours ourselves out
+		over own same she should so some such than that the their theirs them themselves then there these
+		they this those through to too under until up us very via was we were what when where which while
+		who whom why will with would you your yours yourself yourselves im ive youre dont doesnt didnt cant
+		wont isnt arent thats its lol`),
+	"fr": wordSet(`à au aux avec ça ce ces dans de des du elle en et eux il ils je la le les leur lui ma mais
+		me même mes moi mon ne nos notre nous on ou où par pas pour qu que qui sa se ses son sur ta te tes toi
+		ton tu un une vos votre vous c d j l m n s t y est sont été être avoir ai as avons avez ont cette cet
+		plus très`),
+	"es": wordSet(`a al algo como con de del donde dónde el él ella ellos en entre era es esa ese eso esta
+		está este esto fue ha hay la las le les lo los mas más me mi mí muy no nos o para pero por que qué se
+		si sí sin sobre su sus también te tu tú un una uno y ya yo`),
+	"de": wordSet(`aber alle als am an auch auf aus bei bin bis da das dass dem den der des die doch du ein
+		eine einem einen einer es für hat hatte ich ihr im in ist ja kann man mein mich mit nach nicht noch
+		nur oder sich sie sind so über um und uns von vor war was wenn wie wir zu zum zur`),
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
+
+// KeywordExtractor stores the key phrases of each post, scored by TF-IDF against a
+// rolling background corpus of the most recent posts.
+type KeywordExtractor struct {
+	topN int
+
+	mu     sync.Mutex
+	corpus *backgroundCorpus
+}
+
+// NewKeywordExtractor creates an extractor keeping the topN phrases of each post,
+// with document frequencies taken from the last corpusSize posts.
+func NewKeywordExtractor(topN int, corpusSize int) *KeywordExtractor {
+	return &KeywordExtractor{
+		topN:   topN,
+		corpus: newBackgroundCorpus(corpusSize),
+	}
+}
+
+func (ke *KeywordExtractor) Name() string {
+	return "KeywordExtractor"
+}
+
+// Process stores the key phrases of the message text on it.
+func (ke *KeywordExtractor) Process(message *models.ProtoMessage) error {
+	if message.Commit == nil || message.Commit.Record == nil {
+		return nil
+	}
+	record := message.Commit.Record
+
+	language := messageLanguage(record)
+	message.KeyPhrases = ke.Extract(record.Text, record.Facets, language)
+	return nil
+}
+
+// Extract scores the unigrams and bigrams of text, then adds it to the background corpus.
+func (ke *KeywordExtractor) Extract(text string, facets []*models.Facet, language string) []*models.KeyPhrase {
+	terms := phraseTerms(keywordSegments(text, facets), stopwords[language])
+	if len(terms) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, term := range terms {
+		counts[term]++
+	}
+
+	ke.mu.Lock()
+	phrases := make([]*models.KeyPhrase, 0, len(counts))
+	for term, count := range counts {
+		tf := float64(count) / float64(len(terms))
+		phrases = append(phrases, &models.KeyPhrase{Phrase: term, Score: tf * ke.corpus.idf(term)})
+	}
+	ke.corpus.add(counts)
+	ke.mu.Unlock()
+
+	sort.Slice(phrases, func(i, j int) bool {
+		if phrases[i].Score != phrases[j].Score {
+			return phrases[i].Score > phrases[j].Score
+		}
+		return phrases[i].Phrase < phrases[j].Phrase
+	})
+	if ke.topN > 0 && len(phrases) > ke.topN {
+		phrases = phrases[:ke.topN]
+	}
+	return phrases
+}
+
+// messageLanguage prefers the languages declared on the post and detects one otherwise.
+func messageLanguage(record *models.Record) string {
+	for _, langs := range [][]string{record.Langs, record.Lang} {
+		if len(langs) > 0 && langs[0] != "" {
+			language, _, _ := strings.Cut(strings.ToLower(langs[0]), "-")
+			return language
+		}
+	}
+	return detectLanguage(record.Text)
+}
+
+// keywordSegments lowercases text and splits it into runs of words. Facet ranges
+// (mentions, links and tags) and sentence punctuation end a run, so no phrase spans them.
+func keywordSegments(text string, facets []*models.Facet) [][]string {
+	raw := []byte(text)
+	for _, facet := range facets {
+		if facet.Index == nil {
+			continue
+		}
+		start, end := int(facet.Index.ByteStart), int(facet.Index.ByteEnd)
+		if start < 0 || end > len(raw) || start >= end {
+			continue
+		}
+		for i := start; i < end; i++ {
+			raw[i] = '.'
+		}
+	}
+
+	// A range cutting a multi-byte rune leaves invalid bytes, which decode as
+	// unicode.ReplacementChar below and end the run like any other separator.
+	cleaned := strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(string(raw)))
+
+	var segments [][]string
+	var current []string
+	var word strings.Builder
+	flushWord := func() {
+		if word.Len() > 0 {
+			current = append(current, word.String())
+			word.Reset()
+		}
+	}
+	flushSegment := func() {
+		flushWord()
+		if len(current) > 0 {
+			segments = append(segments, current)
+			current = nil
+		}
+	}
+
+	for _, r := range cleaned {
+		switch {
+		case r == unicode.ReplacementChar:
+			flushSegment()
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '/':
+			flushWord()
+		default:
+			flushSegment()
+		}
+	}
+	flushSegment()
+
+	return segments
+}
+
+// phraseTerms returns the candidate unigrams and bigrams of segments. Stopwords and
+// short or numeric tokens are dropped and break bigrams.
+func phraseTerms(segments [][]string, stop map[string]bool) []string {
+	var terms []string
+	for _, segment := range segments {
+		previous := ""
+		for _, token := range segment {
+			if stop[token] || !isKeywordToken(token) {
+				previous = ""
+				continue
+			}
+			terms = append(terms, token)
+			if previous != "" {
+				terms = append(terms, previous+" "+token)
+			}
+			previous = token
+		}
+	}
+	return terms
+}
+
+func isKeywordToken(token string) bool {
+	letters := 0
+	for _, r := range token {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
+
+// backgroundCorpus keeps document frequencies for a sliding window of documents.
+type backgroundCorpus struct {
+	size int
+	docs [][]string
+	next int
+	df   map[string]int
+}
+
+func newBackgroundCorpus(size int) *backgroundCorpus {
+	return &backgroundCorpus{size: size, df: make(map[string]int)}
+}
+
+// idf is the smoothed inverse document frequency of term.
+func (bc *backgroundCorpus) idf(term string) float64 {
+	n := float64(len(bc.docs))
+	return math.Log((1+n)/(1+float64(bc.df[term]))) + 1
+}
+
+// add records a document, evicting the oldest one once the window is full.
+func (bc *backgroundCorpus) add(counts map[string]int) {
+	if bc.size <= 0 {
+		return
+	}
+
+	doc := make([]string, 0, len(counts))
+	for term := range counts {
+		doc = append(doc, term)
+		bc.df[term]++
+	}
+
+	if len(bc.docs) < bc.size {
+		bc.docs = append(bc.docs, doc)
+		return
+	}
+
+	for _, term := range bc.docs[bc.next] {
+		if bc.df[term]--; bc.df[term] <= 0 {
+			delete(bc.df, term)
+		}
+	}
+	bc.docs[bc.next] = doc
+	bc.next = (bc.next + 1) % bc.size
+}
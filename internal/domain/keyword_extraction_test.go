@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+func TestKeywordSegments_SkipsFacets(t *testing.T) {
+	text := "Rate cut from @fed.bsky.social today #markets https://example.com"
+	facets := []*models.Facet{
+		{Index: &models.Index{ByteStart: 14, ByteEnd: 30}},
+		{Index: &models.Index{ByteStart: 37, ByteEnd: 45}},
+		{Index: &models.Index{ByteStart: 46, ByteEnd: 65}},
+	}
+
+	segments := keywordSegments(text, facets)
+
+	expected := [][]string{{"rate", "cut", "from"}, {"today"}}
+	if len(segments) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, segments)
+	}
+	for i := range expected {
+		if len(segments[i]) != len(expected[i]) {
+			t.Fatalf("Expected %v, got %v", expected, segments)
+		}
+		for j := range expected[i] {
+			if segments[i][j] != expected[i][j] {
+				t.Errorf("Expected %v, got %v", expected, segments)
+			}
+		}
+	}
+}
+
+func TestPhraseTerms(t *testing.T) {
+	segments := [][]string{{"the", "federal", "reserve", "cut", "rates", "by", "50", "bps"}}
+
+	terms := phraseTerms(segments, stopwords["en"])
+
+	expected := []string{
+		"federal", "reserve", "federal reserve", "cut", "reserve cut", "rates", "cut rates", "bps",
+	}
+	if len(terms) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, terms)
+	}
+	for i := range expected {
+		if terms[i] != expected[i] {
+			t.Errorf("Expected %q at %d, got %q", expected[i], i, terms[i])
+		}
+	}
+}
+
+func TestKeywordExtractor_TFIDF(t *testing.T) {
+	ke := NewKeywordExtractor(3, 100)
+
+	for range 10 {
+		ke.Extract("Markets today were busy", nil, "en")
+	}
+
+	phrases := ke.Extract("Markets today: Nvidia earnings beat", nil, "en")
+	if len(phrases) != 3 {
+		t.Fatalf("Expected 3 phrases, got %v", phrases)
+	}
+	for _, phrase := range phrases {
+		if phrase.Phrase == "markets" || phrase.Phrase == "today" || phrase.Phrase == "markets today" {
+			t.Errorf("Expected background phrases to rank low, got %v", phrases)
+		}
+	}
+}
+
+func TestBackgroundCorpus_Window(t *testing.T) {
+	bc := newBackgroundCorpus(2)
+
+	bc.add(map[string]int{"a": 1})
+	bc.add(map[string]int{"a": 1, "b": 1})
+	bc.add(map[string]int{"b": 1})
+
+	if len(bc.docs) != 2 {
+		t.Fatalf("Expected 2 documents, got %d", len(bc.docs))
+	}
+	if bc.df["a"] != 1 || bc.df["b"] != 2 {
+		t.Errorf("Unexpected document frequencies: %v", bc.df)
+	}
+}
+
+func TestKeywordExtractor_Process(t *testing.T) {
+	message := &models.ProtoMessage{
+		Commit: &models.Commit{
+			Record: &models.Record{
+				Langs: []string{"en-US"},
+				Text:  "The inflation report is out",
+			},
+		},
+	}
+
+	if err := NewKeywordExtractor(5, 10).Process(message); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	found := false
+	for _, phrase := range message.KeyPhrases {
+		if phrase.Phrase == "the" || phrase.Phrase == "is" {
+			t.Errorf("Expected stopwords to be dropped, got %v", message.KeyPhrases)
+		}
+		if phrase.Phrase == "inflation report" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected the bigram %q, got %v", "inflation report", message.KeyPhrases)
+	}
+}
+
+func TestKeywordExtractor_AccentedStopwords(t *testing.T) {
+	tests := []struct {
+		language string
+		text     string
+		dropped  []string
+	}{
+		{"fr", "Le marché est très calme même avec la BCE", []string{"très", "même"}},
+		{"es", "El mercado también está más tranquilo", []string{"también", "está", "más"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			for _, phrase := range NewKeywordExtractor(10, 10).Extract(tt.text, nil, tt.language) {
+				for _, word := range strings.Fields(phrase.Phrase) {
+					if slices.Contains(tt.dropped, word) {
+						t.Errorf("Expected stopword %q to be dropped, got %q", word, phrase.Phrase)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package domain
 
-import "github.com/pemistahl/lingua-go"
+import (
+	"strings"
+	"sync"
+
+	"github.com/pemistahl/lingua-go"
+)
 
 func isLikelyEnglish(text string) bool {
 	languages := []lingua.Language{
@@ -14,3 +19,24 @@ func isLikelyEnglish(text string) bool {
 	detectedLanguage, exists := detector.DetectLanguageOf(text)
 	return exists && detectedLanguage == lingua.English
 }
+
+var (
+	stopwordDetector     lingua.LanguageDetector
+	stopwordDetectorOnce sync.Once
+)
+
+// detectLanguage returns the ISO 639-1 code of text among the languages that have
+// stopwords, or "" when it cannot tell.
+func detectLanguage(text string) string {
+	stopwordDetectorOnce.Do(func() {
+		stopwordDetector = lingua.NewLanguageDetectorBuilder().
+			FromLanguages(lingua.English, lingua.French, lingua.Spanish, lingua.German).
+			Build()
+	})
+
+	detectedLanguage, exists := stopwordDetector.DetectLanguageOf(text)
+	if !exists {
+		return ""
+	}
+	return strings.ToLower(detectedLanguage.IsoCode639_1().String())
+}
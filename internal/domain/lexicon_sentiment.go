@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+)
+
+// LexiconModelVersion identifies results produced by the built-in word list.
+const LexiconModelVersion = "lexicon-v1"
+
+// Scoring constants follow VADER (Hutto & Gilbert, 2014).
+const (
+	lexiconBoost         = 0.293 // added or removed by intensifiers and dampeners
+	lexiconNegation      = -0.74 // applied to a word preceded by a negation
+	lexiconNegationRange = 3     // how many preceding tokens a negation reaches
+	lexiconAlpha         = 15.0  // normalization constant for the compound score
+	lexiconThreshold     = 0.05  // compound scores closer to 0 are neutral
+)
+
+// financeLexicon is a small Loughran-McDonald style word list for financial text
+// with VADER style valences between -4 and 4.
+var financeLexicon = map[string]float64{
+	// positive
+	"beat": 2.0, "beats": 2.0, "boom": 2.3, "booming": 2.3, "breakout": 1.8,
+	"bullish": 2.6, "buy": 1.2, "gain": 1.9, "gained": 1.9, "gains": 1.9,
+	"growth": 1.8, "grew": 1.6, "high": 0.8, "highs": 1.2, "improve": 1.7,
+	"improved": 1.7, "improvement": 1.7, "moon": 2.0, "outperform": 2.2,
+	"outperformed": 2.2, "profit": 2.0, "profitable": 2.2, "profits": 2.0,
+	"rally": 2.2, "rallied": 2.2, "rebound": 1.6, "record": 1.2, "recover": 1.4,
+	"recovery": 1.5, "rise": 1.4, "rises": 1.4, "rising": 1.4, "rose": 1.4,
+	"soar": 2.5, "soared": 2.5, "soaring": 2.5, "strong": 1.8, "stronger": 1.9,
+	"surge": 2.3, "surged": 2.3, "upbeat": 2.0, "upgrade": 2.1, "upgraded": 2.1,
+	"upside": 1.6, "win": 1.8, "winning": 1.8,
+	// negative
+	"bankrupt": -3.2, "bankruptcy": -3.2, "bearish": -2.6, "collapse": -3.0,
+	"collapsed": -3.0, "crash": -3.0, "crashed": -3.0, "crisis": -2.8,
+	"cut": -1.4, "cuts": -1.4, "decline": -1.8, "declined": -1.8, "declines": -1.8,
+	"default": -2.6, "deficit": -1.7, "delay": -1.2, "delayed": -1.2, "down": -1.0,
+	"downgrade": -2.1, "downgraded": -2.1, "downturn": -2.2, "drop": -1.7,
+	"dropped": -1.7, "dump": -1.9, "fall": -1.6, "fell": -1.6, "falling": -1.6,
+	"fraud": -3.1, "impairment": -2.0, "inflation": -1.2, "investigation": -1.8,
+	"lawsuit": -2.0, "layoff": -2.2, "layoffs": -2.2, "litigation": -1.9,
+	"loss": -2.1, "losses": -2.1, "lost": -1.8, "miss": -1.8, "missed": -1.8,
+	"plunge": -2.6, "plunged": -2.6, "recall": -1.6, "recession": -2.7,
+	"restatement": -2.0, "risk": -1.1, "selloff": -2.3, "sell-off": -2.3,
+	"slump": -2.3, "tumble": -2.2, "tumbled": -2.2, "underperform": -2.0,
+	"volatile": -1.1, "weak": -1.8, "weaker": -1.9, "writedown": -2.2,
+}
+
+var lexiconIntensifiers = map[string]float64{
+	"absolutely": lexiconBoost, "extremely": lexiconBoost, "highly": lexiconBoost,
+	"hugely": lexiconBoost, "massively": lexiconBoost, "really": lexiconBoost,
+	"sharply": lexiconBoost, "significantly": lexiconBoost, "so": lexiconBoost,
+	"strongly": lexiconBoost, "very": lexiconBoost,
+	"barely": -lexiconBoost, "marginally": -lexiconBoost, "slightly": -lexiconBoost,
+	"somewhat": -lexiconBoost,
+}
+
+var lexiconNegations = map[string]bool{
+	"no": true, "not": true, "never": true, "none": true, "neither": true, "nor": true,
+	"without": true, "hardly": true, "cannot": true, "cant": true, "dont": true,
+	"doesnt": true, "didnt": true, "isnt": true, "wasnt": true, "wont": true,
+	"arent": true, "aint": true,
+}
+
+// LexiconSentimentClassifier scores financial sentiment in process from a word
+// list, without calling any service.
+type LexiconSentimentClassifier struct {
+	name    string
+	lexicon map[string]float64
+}
+
+// NewLexiconSentimentClassifier creates a scorer using the built-in finance word list.
+func NewLexiconSentimentClassifier(name string) *LexiconSentimentClassifier {
+	lexicon := make(map[string]float64, len(financeLexicon))
+	for word, valence := range financeLexicon {
+		lexicon[word] = valence
+	}
+	return &LexiconSentimentClassifier{name: name, lexicon: lexicon}
+}
+
+func newLexiconClassifier(spec config.ClassifierSpec) (Classifier, error) {
+	classifier := NewLexiconSentimentClassifier(spec.Name)
+	if spec.Lexicon != "" {
+		if err := classifier.LoadWords(spec.Lexicon); err != nil {
+			return nil, fmt.Errorf("classifier %s: %w", spec.Name, err)
+		}
+	}
+	return classifier, nil
+}
+
+// LoadWords adds or overrides words from a "word,valence" CSV file.
+func (c *LexiconSentimentClassifier) LoadWords(path string) error {
+	f, err := os.Open(path) //nolint:gosec // path comes from configuration
+	if err != nil {
+		return fmt.Errorf("opening lexicon %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading lexicon %s: %w", path, err)
+		}
+
+		valence, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			return fmt.Errorf("reading lexicon %s: invalid valence for %q: %w", path, record[0], err)
+		}
+		c.lexicon[strings.ToLower(strings.TrimSpace(record[0]))] = valence
+	}
+}
+
+func (c *LexiconSentimentClassifier) Name() string {
+	return c.name
+}
+
+func (c *LexiconSentimentClassifier) Classify(text string) (*ClassificationResult, error) {
+	compound := c.Compound(text)
+
+	label := "neutral"
+	confidence := 1 - math.Abs(compound)
+	switch {
+	case compound >= lexiconThreshold:
+		label, confidence = "positive", compound
+	case compound <= -lexiconThreshold:
+		label, confidence = "negative", -compound
+	}
+
+	return &ClassificationResult{
+		Classifier:   c.name,
+		Labels:       []LabelScore{{Label: label, Score: confidence}},
+		ModelVersion: LexiconModelVersion,
+	}, nil
+}
+
+// Compound returns the normalized sentiment of text between -1 and 1.
+func (c *LexiconSentimentClassifier) Compound(text string) float64 {
+	tokens := lexiconTokens(text)
+
+	sum := 0.0
+	for i, token := range tokens {
+		valence, ok := c.lexicon[token]
+		if !ok {
+			continue
+		}
+
+		for back := 1; back <= lexiconNegationRange && i-back >= 0; back++ {
+			previous := tokens[i-back]
+			if boost, ok := lexiconIntensifiers[previous]; ok {
+				// Intensifiers further away count less, as in VADER.
+				scaled := boost * (1 - 0.05*float64(back-1))
+				if valence < 0 {
+					scaled = -scaled
+				}
+				valence += scaled
+			}
+			if lexiconNegations[previous] {
+				valence *= lexiconNegation
+			}
+		}
+
+		sum += valence
+	}
+
+	return sum / math.Sqrt(sum*sum+lexiconAlpha)
+}
+
+// lexiconTokens lowercases text and splits it into words, dropping apostrophes so
+// "don't" matches the negation "dont".
+func lexiconTokens(text string) []string {
+	text = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(text))
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLexiconSentimentClassifier(t *testing.T) {
+	classifier := NewLexiconSentimentClassifier("lexicon")
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "Positive", text: "Shares surged after a record profit", expected: "positive"},
+		{name: "Negative", text: "The stock plunged on fraud investigation", expected: "negative"},
+		{name: "Neutral", text: "The meeting is on Tuesday", expected: "neutral"},
+		{name: "Negated positive", text: "This was not a strong quarter", expected: "negative"},
+		{name: "Negated contraction", text: "Revenue didn't decline", expected: "positive"},
+		{name: "Empty", text: "", expected: "neutral"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := classifier.Classify(tc.text)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.TopLabel() != tc.expected {
+				t.Errorf(
+					"Expected %s for '%s', got %s (%f)",
+					tc.expected,
+					tc.text,
+					result.TopLabel(),
+					classifier.Compound(tc.text),
+				)
+			}
+			if result.ModelVersion != LexiconModelVersion {
+				t.Errorf("Expected results tagged with %s", LexiconModelVersion)
+			}
+		})
+	}
+}
+
+func TestLexiconSentimentClassifier_Intensifiers(t *testing.T) {
+	classifier := NewLexiconSentimentClassifier("lexicon")
+
+	plain := classifier.Compound("earnings were weak")
+	intensified := classifier.Compound("earnings were very weak")
+	dampened := classifier.Compound("earnings were slightly weak")
+
+	if !(intensified < plain && plain < dampened && dampened < 0) {
+		t.Errorf("Unexpected ordering: very %f, plain %f, slightly %f", intensified, plain, dampened)
+	}
+}
+
+func TestLexiconSentimentClassifier_LoadWords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lexicon.csv")
+	if err := os.WriteFile(path, []byte("# custom words\nhodl,2.5\ncrash,0\n"), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	classifier := NewLexiconSentimentClassifier("lexicon")
+	if err := classifier.LoadWords(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if compound := classifier.Compound("HODL"); compound <= 0 {
+		t.Errorf("Expected custom word to be positive, got %f", compound)
+	}
+	if compound := classifier.Compound("crash"); compound != 0 {
+		t.Errorf("Expected overridden word to be neutral, got %f", compound)
+	}
+}
+
+func TestCircuitBreakerClassifier_Fallback(t *testing.T) {
+	now := time.Unix(0, 0)
+	primary := &stubClassifier{name: "bert", err: errors.New("connection refused")}
+	breaker := NewCircuitBreakerClassifier(
+		primary,
+		NewLexiconSentimentClassifier("lexicon"),
+		2,
+		time.Minute,
+	).(*circuitBreakerClassifier)
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		result, err := breaker.Classify("profits soared")
+		if err != nil {
+			t.Fatalf("Expected fallback result, got error: %v", err)
+		}
+		if result.Classifier != "lexicon" || result.TopLabel() != "positive" {
+			t.Errorf("Expected lexicon result, got %+v", result)
+		}
+	}
+	if !breaker.Open() {
+		t.Fatalf("Expected circuit to open after 2 failures")
+	}
+
+	// The service is back but the circuit stays open until the cooldown passes.
+	primary.err = nil
+	primary.labels = []LabelScore{{Label: "positive", Score: 0.9}}
+	if result, _ := breaker.Classify("profits soared"); result.ModelVersion != LexiconModelVersion {
+		t.Errorf("Expected fallback while open, got %+v", result)
+	}
+
+	now = now.Add(2 * time.Minute)
+	result, err := breaker.Classify("profits soared")
+	if err != nil || result.ModelVersion != "v1" {
+		t.Errorf("Expected primary after cooldown, got %+v (%v)", result, err)
+	}
+	if breaker.Open() {
+		t.Errorf("Expected circuit to close after a success")
+	}
+}
+
+func TestCircuitBreakerClassifier_NoFallback(t *testing.T) {
+	breaker := NewCircuitBreakerClassifier(
+		&stubClassifier{name: "bert", err: errors.New("timeout")},
+		nil,
+		1,
+		time.Minute,
+	)
+
+	if _, err := breaker.Classify("text"); err == nil {
+		t.Errorf("Expected error without fallback")
+	}
+	if _, err := breaker.Classify("text"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+}
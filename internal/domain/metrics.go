@@ -1,17 +1,19 @@
 package domain
 
 import (
+	"context"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
 	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/repositories"
 )
 
-// Categories we want to track sentiment for.
-
-// Categories we want to track sentiment for.
+// Categories we want to track sentiment for when none are configured.
 var trackedCategories = []string{"labour", "politics", "economy", "conflict"}
 
 var intervalSecs = 60
@@ -32,7 +34,38 @@ type DataCollector struct {
 	postsSinceLog       int
 	tokensSinceLog      int
 	sentimentSinceLog   map[string]*models.CategoryMetrics
+	tickersSinceLog     map[string]*models.TickerMetrics
 	periodicCallCounter int
+
+	// Optional classification cache whose hit rate is reported with the metrics
+	cache *ClassificationCache
+
+	// Optional message queue whose backpressure is reported with the metrics
+	queue func() QueueStats
+
+	// Optional spool of messages waiting for the broker, reported with the metrics
+	spool func() SpoolStats
+
+	// Optional buffer of messages waiting to be stored, reported with the metrics
+	writes func() repositories.WriteStats
+
+	// Evaluations of each filtering rule since the last log
+	rulesSinceLog map[string]*models.RuleMetrics
+
+	// Optional destination of the snapshot of every interval
+	publish func(*models.IntervalMetrics) error
+}
+
+// QueueStats are the counters of the queue messages wait in for a worker.
+type QueueStats struct {
+	Length    int
+	Capacity  int
+	Busy      int
+	Workers   int
+	Processed int64
+	// Full counts the messages that waited for room in the queue, WaitTime their total wait.
+	Full     int64
+	WaitTime time.Duration
 }
 
 // NewDataCollector creates and initializes a new DataCollector.
@@ -44,10 +77,76 @@ func NewDataCollector(appCtx appcontext.AppContext) *DataCollector {
 		postsSinceLog:       0,
 		tokensSinceLog:      0,
 		sentimentSinceLog:   make(map[string]*models.CategoryMetrics),
+		tickersSinceLog:     make(map[string]*models.TickerMetrics),
+		rulesSinceLog:       make(map[string]*models.RuleMetrics),
 		periodicCallCounter: 0,
 	}
 }
 
+// PublishTo hands the snapshot of every interval to publish, e.g. to send it to the
+// metrics topic.
+func (dc *DataCollector) PublishTo(publish func(*models.IntervalMetrics) error) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.publish = publish
+}
+
+// AddRuleResults counts the results of the filtering rules evaluated for a post.
+func (dc *DataCollector) AddRuleResults(results map[string]bool) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	for rule, passed := range results {
+		metrics, ok := dc.rulesSinceLog[rule]
+		if !ok {
+			metrics = &models.RuleMetrics{Rule: rule}
+			dc.rulesSinceLog[rule] = metrics
+		}
+		metrics.Evaluated++
+		if !passed {
+			metrics.Rejected++
+		}
+	}
+}
+
+// WatchCache reports the stats of the given classification cache on every interval.
+func (dc *DataCollector) WatchCache(cache *ClassificationCache) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.cache = cache
+}
+
+// WatchQueue reports the stats returned by queue on every interval.
+func (dc *DataCollector) WatchQueue(queue func() QueueStats) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.queue = queue
+}
+
+// SpoolStats describe the messages spooled on disk while the broker is unreachable.
+type SpoolStats struct {
+	Messages  int
+	Bytes     int64
+	Segments  int
+	OldestAge time.Duration
+	// Dropped counts the messages deleted because the spool reached its maximum size.
+	Dropped int64
+}
+
+// WatchSpool reports the stats returned by spool on every interval.
+func (dc *DataCollector) WatchSpool(spool func() SpoolStats) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.spool = spool
+}
+
+// WatchWrites reports the stats returned by writes on every interval.
+func (dc *DataCollector) WatchWrites(writes func() repositories.WriteStats) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.writes = writes
+}
+
 // Add safely adds a new data point, updating aggregated metrics.
 func (dc *DataCollector) Add(message *models.ProtoMessage) error {
 	// --- Lock for the remainder of the function to update metrics safely ---
@@ -65,10 +164,13 @@ func (dc *DataCollector) Add(message *models.ProtoMessage) error {
 	dc.tokensSinceLog += messageTokens
 
 	if message.FinSentiment != nil {
+		weighted := dc.AppCtx.Config.MetricsConfidenceWeighted
+		confidence := sentimentConfidence(message)
+
 		// Update sentiment counts for relevant categories
 		for _, category := range message.Categories {
 			// Only track categories we care about
-			if !containsCategory(trackedCategories, category) {
+			if !containsCategory(dc.trackedCategories(), category) {
 				continue
 			}
 
@@ -77,27 +179,73 @@ func (dc *DataCollector) Add(message *models.ProtoMessage) error {
 			}
 
 			sentiment := *message.FinSentiment
+			metrics := dc.sentimentSinceLog[category]
 			switch sentiment {
 			case "positive":
-				dc.sentimentSinceLog[category].Positive++
+				metrics.Positive++
+				if weighted {
+					metrics.WeightedPositive += confidence
+				}
 			case "negative":
-				dc.sentimentSinceLog[category].Negative++
+				metrics.Negative++
+				if weighted {
+					metrics.WeightedNegative += confidence
+				}
+			case UnknownLabel:
+				// Below the classifier's confidence threshold, nothing to count.
 			default:
 				dc.AppCtx.Log.Warn("Unknown sentiment value: %s", sentiment)
 			}
 		}
+
+		// Every mentioned ticker is tracked, there is no fixed list.
+		for _, ticker := range message.Tickers {
+			if dc.tickersSinceLog == nil {
+				dc.tickersSinceLog = make(map[string]*models.TickerMetrics)
+			}
+			if _, ok := dc.tickersSinceLog[ticker]; !ok {
+				dc.tickersSinceLog[ticker] = &models.TickerMetrics{Ticker: ticker}
+			}
+
+			metrics := dc.tickersSinceLog[ticker]
+			switch *message.FinSentiment {
+			case "positive":
+				metrics.Positive++
+				if weighted {
+					metrics.WeightedPositive += confidence
+				}
+			case "negative":
+				metrics.Negative++
+				if weighted {
+					metrics.WeightedNegative += confidence
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
-// LogMetrics logs the currently aggregated metrics and resets the periodic counters.
+// LogMetrics logs the currently aggregated metrics, publishes their snapshot and
+// resets the periodic counters.
 func (dc *DataCollector) LogMetrics(server bool) {
+	snapshot, publish := dc.logMetrics(server)
+
+	// Published without holding the lock, so a slow broker does not block Add.
+	if publish != nil {
+		if err := publish(snapshot); err != nil {
+			dc.AppCtx.Log.Error("Failed to publish metrics", err)
+		}
+	}
+}
+
+func (dc *DataCollector) logMetrics(server bool) (*models.IntervalMetrics, func(*models.IntervalMetrics) error) {
 	// Lock for the entire duration to ensure a consistent snapshot of metrics is logged and reset
 	dc.mu.Lock()
 	defer dc.mu.Unlock()
 
 	dc.periodicCallCounter++
+	snapshot := dc.snapshot(server)
 
 	// --- Log Post and Token Metrics ---
 	dc.AppCtx.Log.Info(
@@ -112,9 +260,72 @@ func (dc *DataCollector) LogMetrics(server bool) {
 		dc.periodicCallCounter,
 	)
 
+	if dc.cache != nil {
+		stats := dc.cache.Stats()
+		dc.AppCtx.Log.Info(
+			"Cache hits: %d     Misses: %d     Hit rate: %.2f     Size: %d     Evictions: %d     Store errors: %d",
+			stats.Hits,
+			stats.Misses,
+			stats.HitRate(),
+			stats.Size,
+			stats.Evictions,
+			stats.StoreErrors,
+		)
+	}
+
+	if dc.queue != nil {
+		stats := dc.queue()
+		dc.AppCtx.Log.Info(
+			"Queue: %d/%d     Busy workers: %d/%d     Processed: %d     Full waits: %d (%s)",
+			stats.Length,
+			stats.Capacity,
+			stats.Busy,
+			stats.Workers,
+			stats.Processed,
+			stats.Full,
+			stats.WaitTime,
+		)
+	}
+
+	if dc.spool != nil {
+		stats := dc.spool()
+		dc.AppCtx.Log.Info(
+			"Spooled: %d     Bytes: %d     Segments: %d     Oldest: %s     Dropped: %d",
+			stats.Messages,
+			stats.Bytes,
+			stats.Segments,
+			stats.OldestAge.Round(time.Second),
+			stats.Dropped,
+		)
+	}
+
+	if dc.writes != nil {
+		stats := dc.writes()
+		dc.AppCtx.Log.Info(
+			"Store buffer: %d/%d     Batches: %d     Written: %d     Failed: %d     Full waits: %d (%s)     Write time: %s",
+			stats.Pending,
+			stats.Capacity,
+			stats.Batches,
+			stats.Written,
+			stats.Failed,
+			stats.Full,
+			stats.WaitTime,
+			stats.FlushTime,
+		)
+	}
+
+	for _, rule := range snapshot.Rules {
+		dc.AppCtx.Log.Info(
+			"Rule: %s     Evaluated: %d     Rejected: %d",
+			rule.Rule,
+			rule.Evaluated,
+			rule.Rejected,
+		)
+	}
+
 	// --- Log Sentiment Metrics for each tracked category ---
 	if server {
-		for _, category := range trackedCategories {
+		for _, category := range dc.trackedCategories() {
 			metrics, ok := dc.sentimentSinceLog[category]
 			if !ok {
 				// If no posts for this category were seen, log zeroes to indicate it's still being tracked.
@@ -126,13 +337,28 @@ func (dc *DataCollector) LogMetrics(server bool) {
 				dc.sentimentSinceLog[category] = metrics
 			}
 
-			metrics.Timestamp = time.Now().Unix()
+			now := time.Now()
+			metrics.Timestamp = now.Unix()
+			metrics.Time = now.UTC()
 			metrics.Print(dc.AppCtx.Log)
 
 			// Persist and publish metrics
-			if err := dc.AppCtx.MetricsRepo.Insert(*metrics); err != nil {
+			if err := dc.AppCtx.CategoryMetricsRepo.Insert(context.Background(), metrics); err != nil {
 				dc.AppCtx.Log.Error("Failed to insert metrics", err)
-				return
+				return snapshot, dc.publish
+			}
+		}
+
+		// Only tickers seen during the interval are reported.
+		for _, metrics := range dc.tickersSinceLog {
+			now := time.Now()
+			metrics.Timestamp = now.Unix()
+			metrics.Time = now.UTC()
+			metrics.Print(dc.AppCtx.Log)
+
+			if err := dc.AppCtx.TickerMetricsRepo.Insert(context.Background(), metrics); err != nil {
+				dc.AppCtx.Log.Error("Failed to insert ticker metrics", err)
+				return snapshot, dc.publish
 			}
 		}
 	}
@@ -140,6 +366,9 @@ func (dc *DataCollector) LogMetrics(server bool) {
 	// --- Reset the "since last log" counters for the next interval ---
 	dc.postsSinceLog = 0
 	dc.tokensSinceLog = 0
+	// Tickers are open ended, so start from an empty map every interval
+	dc.tickersSinceLog = make(map[string]*models.TickerMetrics)
+	dc.rulesSinceLog = make(map[string]*models.RuleMetrics)
 	// Reset sentiment metrics to zero but keep the map structure
 	for category := range dc.sentimentSinceLog {
 		dc.sentimentSinceLog[category] = &models.CategoryMetrics{
@@ -148,6 +377,53 @@ func (dc *DataCollector) LogMetrics(server bool) {
 			Positive: 0,
 		}
 	}
+
+	return snapshot, dc.publish
+}
+
+// snapshot returns the metrics of the current interval. Sentiment is only reported
+// by the server, which classifies the posts.
+func (dc *DataCollector) snapshot(server bool) *models.IntervalMetrics {
+	now := time.Now().Unix()
+	snapshot := &models.IntervalMetrics{
+		Component:    "wss",
+		Timestamp:    now,
+		IntervalSecs: intervalSecs,
+		PostsPerSec:  float64(dc.postsSinceLog) / float64(intervalSecs),
+		TokensPerSec: float64(dc.tokensSinceLog) / float64(intervalSecs),
+		TotalPosts:   dc.totalPosts,
+		TotalTokens:  dc.totalTokens,
+	}
+
+	if server {
+		snapshot.Component = "server"
+		for _, category := range dc.trackedCategories() {
+			metrics := models.CategoryMetrics{Category: category}
+			if current, ok := dc.sentimentSinceLog[category]; ok {
+				metrics = *current
+			}
+			metrics.Timestamp = now
+			snapshot.Categories = append(snapshot.Categories, metrics)
+		}
+
+		for _, current := range dc.tickersSinceLog {
+			metrics := *current
+			metrics.Timestamp = now
+			snapshot.Tickers = append(snapshot.Tickers, metrics)
+		}
+		sort.Slice(snapshot.Tickers, func(i, j int) bool {
+			return snapshot.Tickers[i].Ticker < snapshot.Tickers[j].Ticker
+		})
+	}
+
+	for _, metrics := range dc.rulesSinceLog {
+		snapshot.Rules = append(snapshot.Rules, *metrics)
+	}
+	sort.Slice(snapshot.Rules, func(i, j int) bool {
+		return snapshot.Rules[i].Rule < snapshot.Rules[j].Rule
+	})
+
+	return snapshot
 }
 
 // StartMetrics begins the periodic logging of collected metrics.
@@ -156,7 +432,7 @@ func (dc *DataCollector) StartMetrics(appCtx appcontext.AppContext, server bool)
 	dc.AppCtx.Log.Info("Metrics collection started")
 
 	// Initialize sentimentSinceLog with empty metrics for tracked categories
-	for _, category := range trackedCategories {
+	for _, category := range dc.trackedCategories() {
 		dc.sentimentSinceLog[category] = &models.CategoryMetrics{
 			Category: category,
 			Negative: 0,
@@ -180,6 +456,24 @@ func (dc *DataCollector) StartMetrics(appCtx appcontext.AppContext, server bool)
 	return nil
 }
 
+// trackedCategories returns the configured categories or the defaults.
+func (dc *DataCollector) trackedCategories() []string {
+	if len(dc.AppCtx.Config.MetricsTrackedCategories) > 0 {
+		return dc.AppCtx.Config.MetricsTrackedCategories
+	}
+	return trackedCategories
+}
+
+// sentimentConfidence returns the score of the stored sentiment label, or 1 when the
+// classifier did not report one so weighted counts degrade to plain counts.
+func sentimentConfidence(message *models.ProtoMessage) float64 {
+	classification, ok := message.Classifications[config.FieldFinSentiment]
+	if !ok || len(classification.Scores) == 0 {
+		return 1
+	}
+	return classification.Scores[0]
+}
+
 // Helper function to check if a category exists in the categories slice.
 func containsCategory(categories []string, category string) bool {
 	for _, cat := range categories {
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/logger"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+func newSentimentMessage(sentiment string, score float64, categories ...string) *models.ProtoMessage {
+	return &models.ProtoMessage{
+		Commit: &models.Commit{
+			Record: &models.Record{Text: "stocks are up today"},
+		},
+		Categories:   categories,
+		FinSentiment: &sentiment,
+		Classifications: map[string]*models.Classification{
+			config.FieldFinSentiment: {Labels: []string{sentiment}, Scores: []float64{score}},
+		},
+	}
+}
+
+func TestDataCollector_ConfidenceWeighted(t *testing.T) {
+	dc := NewDataCollector(appcontext.AppContext{
+		Config: config.AppConfig{MetricsConfidenceWeighted: true},
+	})
+
+	messages := []*models.ProtoMessage{
+		newSentimentMessage("positive", 0.9, "economy"),
+		newSentimentMessage("positive", 0.5, "economy", "politics"),
+		newSentimentMessage("negative", 0.8, "economy"),
+		newSentimentMessage(UnknownLabel, 0.2, "economy"),
+	}
+	for _, message := range messages {
+		if err := dc.Add(message); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	economy := dc.sentimentSinceLog["economy"]
+	if economy.Positive != 2 || economy.Negative != 1 {
+		t.Errorf("Unexpected counts: %+v", economy)
+	}
+	if economy.WeightedPositive != 1.4 || economy.WeightedNegative != 0.8 {
+		t.Errorf("Unexpected weighted counts: %+v", economy)
+	}
+
+	politics := dc.sentimentSinceLog["politics"]
+	if politics.Positive != 1 || politics.WeightedPositive != 0.5 {
+		t.Errorf("Unexpected politics metrics: %+v", politics)
+	}
+}
+
+func TestDataCollector_Unweighted(t *testing.T) {
+	dc := NewDataCollector(appcontext.AppContext{})
+
+	if err := dc.Add(newSentimentMessage("negative", 0.8, "economy")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	economy := dc.sentimentSinceLog["economy"]
+	if economy.Negative != 1 || economy.WeightedNegative != 0 {
+		t.Errorf("Unexpected metrics: %+v", economy)
+	}
+}
+
+func TestDataCollector_Tickers(t *testing.T) {
+	dc := NewDataCollector(appcontext.AppContext{
+		Config: config.AppConfig{MetricsTrackedCategories: []string{"technology"}},
+	})
+
+	positive := newSentimentMessage("positive", 0.9, "technology")
+	positive.Tickers = []string{"AAPL", "MSFT"}
+	negative := newSentimentMessage("negative", 0.7, "economy")
+	negative.Tickers = []string{"AAPL"}
+
+	for _, message := range []*models.ProtoMessage{positive, negative} {
+		if err := dc.Add(message); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	aapl := dc.tickersSinceLog["AAPL"]
+	if aapl == nil || aapl.Positive != 1 || aapl.Negative != 1 {
+		t.Errorf("Unexpected AAPL metrics: %+v", aapl)
+	}
+	if msft := dc.tickersSinceLog["MSFT"]; msft == nil || msft.Positive != 1 {
+		t.Errorf("Unexpected MSFT metrics: %+v", msft)
+	}
+
+	if _, ok := dc.sentimentSinceLog["technology"]; !ok {
+		t.Errorf("Expected configured category to be tracked")
+	}
+	if _, ok := dc.sentimentSinceLog["economy"]; ok {
+		t.Errorf("Expected default categories to be replaced by the configured ones")
+	}
+}
+
+func TestDataCollector_PublishesSnapshot(t *testing.T) {
+	dc := NewDataCollector(appcontext.AppContext{Log: logger.NewLogger()})
+
+	var published []*models.IntervalMetrics
+	dc.PublishTo(func(metrics *models.IntervalMetrics) error {
+		published = append(published, metrics)
+		return nil
+	})
+
+	for range 3 {
+		if err := dc.Add(newSentimentMessage("negative", 0.8, "economy")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	dc.AddRuleResults(map[string]bool{"English posts only": true, "Minimum length": false})
+	dc.AddRuleResults(map[string]bool{"English posts only": false, "Minimum length": false})
+
+	dc.LogMetrics(false)
+	dc.LogMetrics(false)
+
+	if len(published) != 2 {
+		t.Fatalf("Expected a snapshot per interval, got %d", len(published))
+	}
+
+	first := published[0]
+	if first.Component != "wss" || first.TotalPosts != 3 || first.PostsPerSec != 3/float64(intervalSecs) {
+		t.Errorf("Unexpected snapshot: %+v", first)
+	}
+	if len(first.Categories) != 0 {
+		t.Errorf("Expected sentiment to be reported by the server only, got %+v", first.Categories)
+	}
+	expected := []models.RuleMetrics{
+		{Rule: "English posts only", Evaluated: 2, Rejected: 1},
+		{Rule: "Minimum length", Evaluated: 2, Rejected: 2},
+	}
+	if len(first.Rules) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, first.Rules)
+	}
+	for i, rule := range first.Rules {
+		if rule != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], rule)
+		}
+	}
+
+	second := published[1]
+	if second.PostsPerSec != 0 || second.TotalPosts != 3 || len(second.Rules) != 0 {
+		t.Errorf("Expected the interval counters to be reset, got %+v", second)
+	}
+}
@@ -0,0 +1,275 @@
+package domain
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// Modes for links, mentions and emoji.
+const (
+	NormalizeKeep     = "keep"
+	NormalizeStrip    = "strip"
+	NormalizeReplace  = "replace"
+	NormalizeCollapse = "collapse"
+)
+
+// Placeholders used by NormalizeReplace, the convention of the tweet trained models.
+const (
+	linkPlaceholder    = "http"
+	mentionPlaceholder = "@user"
+)
+
+// htmlTagRegex matches opening, closing and self closing tags and comments, but not
+// a lone "<" used as less than.
+var htmlTagRegex = regexp.MustCompile(`<(?:[a-zA-Z/!][^<>]*)>`)
+
+// NormalizationStep is a single transformation of the text sent to the classifiers.
+type NormalizationStep struct {
+	Description string
+	Apply       func(text string, message *models.ProtoMessage) string
+}
+
+// TextNormalizer runs its steps in order on the text of a message. The message
+// itself is never modified so the original text is what gets stored.
+type TextNormalizer struct {
+	steps     []NormalizationStep
+	maxTokens int
+	// html is set when a step unescapes HTML.
+	html bool
+}
+
+// NewTextNormalizer creates an empty normalizer.
+func NewTextNormalizer() *TextNormalizer {
+	return &TextNormalizer{}
+}
+
+// AddStep adds a new step to the normalizer.
+func (tn *TextNormalizer) AddStep(
+	description string,
+	apply func(text string, message *models.ProtoMessage) string,
+) {
+	tn.steps = append(tn.steps, NormalizationStep{Description: description, Apply: apply})
+}
+
+// Steps returns the registered steps in order.
+func (tn *TextNormalizer) Steps() []NormalizationStep {
+	return tn.steps
+}
+
+// NormalizeAll applies every step to text, then truncates it to the token budget.
+func (tn *TextNormalizer) NormalizeAll(text string, message *models.ProtoMessage) string {
+	return tn.truncate(tn.clean(text, message))
+}
+
+// clean applies every step to text. message may be nil for text that is not the
+// post's own, which skips the facet based steps.
+func (tn *TextNormalizer) clean(text string, message *models.ProtoMessage) string {
+	for _, step := range tn.steps {
+		text = step.Apply(text, message)
+	}
+	return text
+}
+
+// truncate cuts text to the token budget, when one is configured.
+func (tn *TextNormalizer) truncate(text string) string {
+	if tn.maxTokens <= 0 {
+		return text
+	}
+	return truncateTokens(text, tn.maxTokens)
+}
+
+// InitNormalizer builds the normalization steps from the configuration. Facet indices
+// are byte offsets into the original text, so links and mentions are handled first.
+func InitNormalizer(cfg *config.AppConfig) (*TextNormalizer, error) {
+	for name, mode := range map[string]string{
+		"TEXT_NORMALIZE_LINKS":    cfg.TextNormalizeLinks,
+		"TEXT_NORMALIZE_MENTIONS": cfg.TextNormalizeMentions,
+	} {
+		if mode != NormalizeKeep && mode != NormalizeStrip && mode != NormalizeReplace {
+			return nil, fmt.Errorf("%s: unknown mode %q", name, mode)
+		}
+	}
+	if mode := cfg.TextNormalizeEmoji; mode != NormalizeKeep && mode != NormalizeStrip &&
+		mode != NormalizeCollapse {
+		return nil, fmt.Errorf("TEXT_NORMALIZE_EMOJI: unknown mode %q", mode)
+	}
+
+	tn := NewTextNormalizer()
+
+	if cfg.TextNormalizeLinks != NormalizeKeep || cfg.TextNormalizeMentions != NormalizeKeep {
+		links, mentions := cfg.TextNormalizeLinks, cfg.TextNormalizeMentions
+		tn.AddStep("Links and mentions", func(text string, message *models.ProtoMessage) string {
+			return replaceFacets(text, messageFacets(message), links, mentions)
+		})
+	}
+
+	if cfg.TextNormalizeHTML {
+		tn.html = true
+		tn.AddStep("Unescape HTML", func(text string, message *models.ProtoMessage) string {
+			return unescapeHTML(text)
+		})
+	}
+
+	if cfg.TextNormalizeEmoji != NormalizeKeep {
+		mode := cfg.TextNormalizeEmoji
+		tn.AddStep("Emoji "+mode, func(text string, message *models.ProtoMessage) string {
+			return normalizeEmoji(text, mode)
+		})
+	}
+
+	tn.AddStep("Collapse whitespace", func(text string, message *models.ProtoMessage) string {
+		return strings.Join(strings.Fields(text), " ")
+	})
+
+	// Truncation is not a step: it applies once to the whole composed input.
+	tn.maxTokens = cfg.TextNormalizeMaxTokens
+
+	return tn, nil
+}
+
+func messageFacets(message *models.ProtoMessage) []*models.Facet {
+	if message == nil || message.Commit == nil || message.Commit.Record == nil {
+		return nil
+	}
+	return message.Commit.Record.Facets
+}
+
+type facetSpan struct {
+	start, end  int
+	replacement string
+}
+
+// replaceFacets strips or replaces the link and mention ranges of text. Facets that
+// do not fit the text, or overlap an earlier one, are ignored.
+func replaceFacets(text string, facets []*models.Facet, links string, mentions string) string {
+	var spans []facetSpan
+	for _, facet := range facets {
+		if facet.Index == nil {
+			continue
+		}
+		start, end := int(facet.Index.ByteStart), int(facet.Index.ByteEnd)
+		if start < 0 || end > len(text) || start >= end {
+			continue
+		}
+
+		mode, placeholder := NormalizeKeep, ""
+		for _, feature := range facet.Features {
+			switch {
+			case feature.Did != nil || strings.HasSuffix(feature.GetType(), "#mention"):
+				mode, placeholder = mentions, mentionPlaceholder
+			case feature.Uri != nil || feature.Link != nil || strings.HasSuffix(feature.GetType(), "#link"):
+				mode, placeholder = links, linkPlaceholder
+			}
+		}
+
+		switch mode {
+		case NormalizeStrip:
+			spans = append(spans, facetSpan{start: start, end: end})
+		case NormalizeReplace:
+			spans = append(spans, facetSpan{start: start, end: end, replacement: placeholder})
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var sb strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span.start < last {
+			continue
+		}
+		sb.WriteString(text[last:span.start])
+		sb.WriteString(span.replacement)
+		last = span.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// unescapeHTML drops tags, as found in bridged posts, and decodes entities.
+func unescapeHTML(text string) string {
+	if strings.ContainsRune(text, '<') {
+		text = htmlTagRegex.ReplaceAllString(text, " ")
+	}
+	if strings.ContainsRune(text, '&') {
+		text = html.UnescapeString(text)
+	}
+	return text
+}
+
+// isEmoji reports whether r is a pictograph or one of the modifiers emoji sequences
+// are built from.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, symbols, skin tones
+		r >= 0x2600 && r <= 0x27BF,   // miscellaneous symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF,   // arrows and stars
+		r == 0x200D,                  // zero width joiner
+		r >= 0xFE00 && r <= 0xFE0F,   // variation selectors
+		r >= 0xE0020 && r <= 0xE007F: // tag sequences
+		return true
+	}
+	return false
+}
+
+// normalizeEmoji strips emoji or collapses runs of a repeated emoji to a single one.
+func normalizeEmoji(text string, mode string) string {
+	var sb strings.Builder
+	var previous rune
+	for _, r := range text {
+		if !isEmoji(r) {
+			sb.WriteRune(r)
+			previous = 0
+			continue
+		}
+		if mode == NormalizeStrip {
+			continue
+		}
+		if r == previous {
+			continue
+		}
+		sb.WriteRune(r)
+		previous = r
+	}
+	return sb.String()
+}
+
+// estimateTokens approximates the sub-word tokens of a word, about four characters
+// per token for BPE and WordPiece vocabularies.
+func estimateTokens(word string) int {
+	runes := 0
+	for _, r := range word {
+		if !unicode.IsSpace(r) {
+			runes++
+		}
+	}
+	return max(1, (runes+3)/4)
+}
+
+// truncateTokens cuts text after the last whole word that fits in maxTokens. A first
+// word longer than the whole budget is cut mid-word rather than dropped.
+func truncateTokens(text string, maxTokens int) string {
+	tokens := 0
+	end := 0
+	for _, word := range strings.Fields(text) {
+		tokens += estimateTokens(word)
+		if tokens > maxTokens {
+			if end == 0 {
+				return string([]rune(word)[:maxTokens*4])
+			}
+			break
+		}
+		end = strings.Index(text[end:], word) + end + len(word)
+	}
+	return text[:end]
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+func normalizationConfig() *config.AppConfig {
+	return &config.AppConfig{
+		TextNormalization:     true,
+		TextNormalizeLinks:    NormalizeReplace,
+		TextNormalizeMentions: NormalizeReplace,
+		TextNormalizeHTML:     true,
+		TextNormalizeEmoji:    NormalizeCollapse,
+	}
+}
+
+func facetMessage(text string, facets ...*models.Facet) *models.ProtoMessage {
+	return &models.ProtoMessage{
+		Commit: &models.Commit{Record: &models.Record{Text: text, Facets: facets}},
+	}
+}
+
+func facetAt(text string, target string, feature *models.Feature) *models.Facet {
+	start := strings.Index(text, target)
+	return &models.Facet{
+		Index:    &models.Index{ByteStart: int32(start), ByteEnd: int32(start + len(target))},
+		Features: []*models.Feature{feature},
+	}
+}
+
+func TestTextNormalizer_Facets(t *testing.T) {
+	text := "Thanks @alice.bsky.social, read https://example.com/a-long-path… #markets"
+	did := "did:plc:alice"
+	uri := "https://example.com/a-long-path?utm_source=x"
+	tag := "markets"
+	message := facetMessage(text,
+		facetAt(text, "@alice.bsky.social", &models.Feature{Did: &did}),
+		facetAt(text, "https://example.com/a-long-path…", &models.Feature{Uri: &uri}),
+		facetAt(text, "#markets", &models.Feature{Tag: &tag}),
+	)
+
+	testCases := []struct {
+		name     string
+		links    string
+		mentions string
+		expected string
+	}{
+		{name: "Replace", links: NormalizeReplace, mentions: NormalizeReplace, expected: "Thanks @user, read http #markets"},
+		{name: "Strip", links: NormalizeStrip, mentions: NormalizeStrip, expected: "Thanks , read #markets"},
+		{name: "Keep mentions", links: NormalizeStrip, mentions: NormalizeKeep, expected: "Thanks @alice.bsky.social, read #markets"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := normalizationConfig()
+			cfg.TextNormalizeLinks, cfg.TextNormalizeMentions = tc.links, tc.mentions
+			tn, err := InitNormalizer(cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if normalized := tn.NormalizeAll(text, message); normalized != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, normalized)
+			}
+			if message.Commit.Record.Text != text {
+				t.Errorf("Expected the original text to be preserved, got %q", message.Commit.Record.Text)
+			}
+		})
+	}
+}
+
+func TestTextNormalizer_Steps(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "HTML", text: "<p>Rates &amp; bonds</p><br/>up", expected: "Rates & bonds up"},
+		{name: "Less than", text: "if a < b and c > d", expected: "if a < b and c > d"},
+		{name: "Emoji runs", text: "To the moon 🚀🚀🚀🚀 📈📈", expected: "To the moon 🚀 📈"},
+		{name: "Whitespace", text: "  many\n\n   spaces\t here ", expected: "many spaces here"},
+	}
+
+	tn, err := InitNormalizer(normalizationConfig())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if normalized := tn.NormalizeAll(tc.text, facetMessage(tc.text)); normalized != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, normalized)
+			}
+		})
+	}
+}
+
+func TestTruncateTokens(t *testing.T) {
+	testCases := []struct {
+		name      string
+		text      string
+		maxTokens int
+		expected  string
+	}{
+		{name: "Fits", text: "one two three", maxTokens: 10, expected: "one two three"},
+		{name: "Word boundary", text: "one two three four", maxTokens: 4, expected: "one two three"},
+		{name: "Long words", text: "internationalization is long", maxTokens: 5, expected: "internationalization"},
+		{name: "Oversized first word", text: "supercalifragilistic", maxTokens: 2, expected: "supercal"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if truncated := truncateTokens(tc.text, tc.maxTokens); truncated != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, truncated)
+			}
+		})
+	}
+}
+
+func TestInitNormalizer_InvalidMode(t *testing.T) {
+	cfg := normalizationConfig()
+	cfg.TextNormalizeEmoji = "explode"
+
+	if _, err := InitNormalizer(cfg); err == nil {
+		t.Error("Expected an error for an unknown emoji mode")
+	}
+}
+
+func TestTextProcessorFactory_Text(t *testing.T) {
+	message := facetMessage("  Hello   world  ")
+
+	if text, _ := NewTextProcessorFactory().Text(message); text != "  Hello   world  " {
+		t.Errorf("Expected the text unchanged without normalization, got %q", text)
+	}
+
+	tpf, err := InitProcessors(normalizationConfig(), nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text, _ := tpf.Text(message); text != "Hello world" {
+		t.Errorf("Expected normalized text, got %q", text)
+	}
+}
+
+func TestTextProcessorFactory_TextBridgyOriginal(t *testing.T) {
+	message := facetMessage("Rates are going up, what does it mean for… https://mastodon.social/@fed/1")
+	message.Commit.Record.BridgyOriginalText = strPtr(
+		"<p>Rates are going up, what does it mean for <b>bank</b> stocks &amp; bonds?</p>",
+	)
+
+	for _, html := range []bool{true, false} {
+		cfg := normalizationConfig()
+		cfg.TextNormalizeHTML = html
+		tpf, err := InitProcessors(cfg, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := "Rates are going up, what does it mean for bank stocks & bonds?"
+		if text, _ := tpf.Text(message); text != want {
+			t.Errorf("Expected the normalized original text %q, got %q", want, text)
+		}
+	}
+}
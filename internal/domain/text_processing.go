@@ -2,19 +2,37 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
-	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier"
 	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/repositories"
 )
 
+// TextProcessor binds a classifier to the message field its output is stored under.
 type TextProcessor struct {
 	Description string
-	Evaluate    func(text string) (string, error)
+	Field       string
+	MinScore    float64
+	Classifier  Classifier
+	// Input composes the text this classifier sees, nil uses the factory default.
+	Input *InputComposer
+}
+
+// MessageProcessor enriches a message from its structured fields rather than its text.
+type MessageProcessor interface {
+	Name() string
+	Process(message *models.ProtoMessage) error
 }
 
 // helps create and manage processors.
 type TextProcessorFactory struct {
 	processors []TextProcessor
+	enrichers  []MessageProcessor
+	normalizer *TextNormalizer
+	input      *InputComposer
+	cache      *ClassificationCache
 }
 
 // creates a new factory.
@@ -22,69 +40,259 @@ func NewTextProcessorFactory() *TextProcessorFactory {
 	return &TextProcessorFactory{}
 }
 
-// AddProcessor adds a new rule to the factory.
-func (tpf *TextProcessorFactory) AddProcessor(description string, evaluate func(text string) (string, error)) {
-	tpf.processors = append(tpf.processors, TextProcessor{Description: description, Evaluate: evaluate})
+// AddProcessor adds a new classifier to the factory. Labels scoring below minScore
+// are reported as UnknownLabel, a minScore of 0 disables the threshold.
+func (tpf *TextProcessorFactory) AddProcessor(field string, minScore float64, classifier Classifier) {
+	tpf.AddComposedProcessor(field, minScore, classifier, nil)
+}
+
+// AddComposedProcessor adds a classifier fed by its own input composition.
+func (tpf *TextProcessorFactory) AddComposedProcessor(
+	field string,
+	minScore float64,
+	classifier Classifier,
+	input *InputComposer,
+) {
+	tpf.processors = append(tpf.processors, TextProcessor{
+		Description: classifier.Name(),
+		Field:       field,
+		MinScore:    minScore,
+		Classifier:  classifier,
+		Input:       input,
+	})
 }
 
-// and a map of rule descriptions to results.
-func (tpf *TextProcessorFactory) ProcessAll(text string) (map[string]string, error) {
-	results := make(map[string]string)
+// AddMessageProcessor adds a processor run by EnrichAll.
+func (tpf *TextProcessorFactory) AddMessageProcessor(processor MessageProcessor) {
+	tpf.enrichers = append(tpf.enrichers, processor)
+}
+
+// Text returns the default classification input of message, normalized when
+// normalization is enabled. The message itself keeps its original text.
+func (tpf *TextProcessorFactory) Text(message *models.ProtoMessage) (string, error) {
+	return tpf.compose(tpf.input, tpf.classificationInput(message))
+}
+
+func (tpf *TextProcessorFactory) classificationInput(message *models.ProtoMessage) ClassificationInput {
+	input := NewClassificationInput(message)
+	if tpf.normalizer != nil {
+		input = input.normalize(tpf.normalizer, message)
+	}
+	return input
+}
+
+func (tpf *TextProcessorFactory) compose(composer *InputComposer, input ClassificationInput) (string, error) {
+	text := input.Text
+	if composer != nil {
+		var err error
+		if text, err = composer.Compose(input); err != nil {
+			return "", err
+		}
+	}
+	if tpf.normalizer != nil {
+		text = tpf.normalizer.truncate(text)
+	}
+	return text, nil
+}
+
+// Cache returns the classification cache, nil when caching is disabled.
+func (tpf *TextProcessorFactory) Cache() *ClassificationCache {
+	return tpf.cache
+}
+
+// Processors returns the registered processors in evaluation order.
+func (tpf *TextProcessorFactory) Processors() []TextProcessor {
+	return tpf.processors
+}
+
+// ProcessAll runs every classifier against the text. Results from classifiers that
+// succeeded are always returned; failures are joined into the returned error.
+func (tpf *TextProcessorFactory) ProcessAll(text string) ([]*ClassificationResult, error) {
+	results := make([]*ClassificationResult, 0, len(tpf.processors))
+	var errs []error
+
 	for _, tp := range tpf.processors {
-		val, _ := tp.Evaluate(text)
-		results[tp.Description] = val
+		result, err := tp.process(text)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, result)
 	}
-	return results, nil
+
+	return results, errors.Join(errs...)
 }
 
-func InitProcessors(cfg *config.AppConfig) *TextProcessorFactory {
-	tpf := NewTextProcessorFactory()
+// ProcessMessage runs every classifier against its composition of the message. Each
+// distinct composition is rendered once and shared by the classifiers using it.
+func (tpf *TextProcessorFactory) ProcessMessage(
+	message *models.ProtoMessage,
+) ([]*ClassificationResult, error) {
+	results, _, err := tpf.ProcessMessageWith(message, func(fn func() error) (int, error) {
+		return 1, fn()
+	})
+	return results, err
+}
 
-	if cfg.TextCategoryClassifier {
-		tpf.AddProcessor("TextCategoryClassifier", func(text string) (string, error) {
-			// api call to model
-			data := mlclassifier.DataRequest{
-				Items: []mlclassifier.DataRequestItem{
-					{Text: text},
-				},
-			}
-			client := mlclassifier.NewClient(cfg.TextCategoryClassifierURL)
-			resp, err := client.Classify(data)
+// ProcessMessageWith is ProcessMessage calling each classifier through retry, so a
+// failing classifier is run again without running the others. It also returns the
+// most attempts retry made for a classifier.
+func (tpf *TextProcessorFactory) ProcessMessageWith(
+	message *models.ProtoMessage,
+	retry func(fn func() error) (int, error),
+) ([]*ClassificationResult, int, error) {
+	input := tpf.classificationInput(message)
+	texts := make(map[*InputComposer]string)
 
-			if err != nil {
-				return "", err
-			}
+	results := make([]*ClassificationResult, 0, len(tpf.processors))
+	var errs []error
+	attempts := 1
 
-			if resp == nil || (*resp)[0].Label == "" {
-				return "", errors.New("response is nil in API call")
+	for _, tp := range tpf.processors {
+		composer := tp.Input
+		if composer == nil {
+			composer = tpf.input
+		}
+
+		text, ok := texts[composer]
+		if !ok {
+			var err error
+			if text, err = tpf.compose(composer, input); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", tp.Description, err))
+				continue
 			}
+			texts[composer] = text
+		}
 
-			return (*resp)[0].Label, nil
+		var result *ClassificationResult
+		tries, err := retry(func() error {
+			var processErr error
+			result, processErr = tp.process(text)
+			return processErr
 		})
+		attempts = max(attempts, tries)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results = append(results, result)
 	}
 
-	if cfg.TextFinSentimentClassifier {
-		tpf.AddProcessor("TextFinSentimentClassifier", func(text string) (string, error) {
-			// api call to model
-			data := mlclassifier.DataRequest{
-				Items: []mlclassifier.DataRequestItem{
-					{Text: text},
-				},
-			}
-			client := mlclassifier.NewClient(cfg.TextFinSentimentClassifierURL)
-			resp, err := client.Classify(data)
+	return results, attempts, errors.Join(errs...)
+}
+
+func (tp TextProcessor) process(text string) (*ClassificationResult, error) {
+	start := time.Now()
+	result, err := tp.Classifier.Classify(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", tp.Description, err)
+	}
+
+	if result.Latency == 0 {
+		result.Latency = time.Since(start)
+	}
+	if result.Classifier == "" {
+		result.Classifier = tp.Description
+	}
+	result.Field = tp.Field
+	result.applyThreshold(tp.MinScore)
+	return result, nil
+}
+
+// EnrichAll runs every message processor, joining their failures into the returned error.
+func (tpf *TextProcessorFactory) EnrichAll(message *models.ProtoMessage) error {
+	var errs []error
+	for _, mp := range tpf.enrichers {
+		if err := mp.Process(message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", mp.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
 
-			if err != nil {
-				return "", err
+// InitProcessors builds the text processors from the configured classifiers. When
+// caching is enabled every remote classifier is wrapped by a shared classification
+// cache, store is its optional second level and may be nil.
+func InitProcessors(
+	cfg *config.AppConfig,
+	store repositories.ClassificationStore,
+) (*TextProcessorFactory, error) {
+	tpf := NewTextProcessorFactory()
+
+	if cfg.TextNormalization {
+		normalizer, err := InitNormalizer(cfg)
+		if err != nil {
+			return nil, err
+		}
+		tpf.normalizer = normalizer
+	}
+
+	if cfg.TextInputTemplate != "" {
+		input, err := NewInputComposer(cfg.TextInputTemplate)
+		if err != nil {
+			return nil, err
+		}
+		tpf.input = input
+	}
+
+	if cfg.ClassifierCacheEnabled {
+		if cfg.ClassifierCacheTTL <= 0 {
+			return nil, fmt.Errorf(
+				"CLASSIFIER_CACHE_TTL must be positive when the cache is enabled, got %s",
+				cfg.ClassifierCacheTTL,
+			)
+		}
+		tpf.cache = NewClassificationCache(cfg.ClassifierCacheSize, cfg.ClassifierCacheTTL, store)
+	}
+
+	for _, spec := range cfg.Classifiers {
+		classifier, err := NewClassifier(spec)
+		if err != nil {
+			return nil, err
+		}
+		if spec.CircuitBreakerFailures > 0 {
+			var fallback Classifier
+			if spec.Fallback != "" {
+				fallback, err = NewClassifier(spec.FallbackSpec())
+				if err != nil {
+					return nil, fmt.Errorf("classifier %s fallback: %w", spec.Name, err)
+				}
+			}
+			classifier = NewCircuitBreakerClassifier(
+				classifier,
+				fallback,
+				spec.CircuitBreakerFailures,
+				spec.CircuitBreakerCooldown,
+			)
+		}
+		if tpf.cache != nil && spec.IsRemote() {
+			// Wrapped outside the circuit breaker so cached results are still served
+			// while the circuit is open.
+			classifier = NewCachedClassifier(classifier, spec.ModelVersion, tpf.cache)
+		}
+		var input *InputComposer
+		if spec.InputTemplate != "" {
+			if input, err = NewInputComposer(spec.InputTemplate); err != nil {
+				return nil, fmt.Errorf("classifier %s: %w", spec.Name, err)
 			}
+		}
+		tpf.AddComposedProcessor(spec.Field, spec.MinScore, classifier, input)
+	}
 
-			if resp == nil {
-				return "", errors.New("response is nil in API call")
+	if cfg.TextURLExtractor {
+		domainKinds := defaultDomainKinds
+		if cfg.TextURLDomainsFile != "" {
+			var err error
+			if domainKinds, err = LoadDomainKinds(cfg.TextURLDomainsFile); err != nil {
+				return nil, err
 			}
+		}
+		tpf.AddMessageProcessor(NewURLExtractor(domainKinds))
+	}
 
-			return (*resp)[0].Label, nil
-		})
+	if cfg.TextKeywordExtractor {
+		tpf.AddMessageProcessor(NewKeywordExtractor(cfg.TextKeywordTopN, cfg.TextKeywordCorpusSize))
 	}
 
-	return tpf
+	return tpf, nil
 }
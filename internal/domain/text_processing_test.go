@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier/classifierpb"
+	"stockseer.ai/blueksy-firehose/internal/apis/mlclassifier/mlclassifiertest"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+type stubClassifier struct {
+	name   string
+	labels []LabelScore
+	err    error
+	texts  []string
+}
+
+func (s *stubClassifier) Name() string {
+	return s.name
+}
+
+func (s *stubClassifier) Classify(text string) (*ClassificationResult, error) {
+	s.texts = append(s.texts, text)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &ClassificationResult{Labels: s.labels, ModelVersion: "v1"}, nil
+}
+
+func TestTextProcessorFactory_ProcessAll(t *testing.T) {
+	tpf := NewTextProcessorFactory()
+	tpf.AddProcessor(config.FieldCategories, 0, &stubClassifier{
+		name:   "category",
+		labels: []LabelScore{{Label: "politics, government", Score: 0.9}},
+	})
+	tpf.AddProcessor(config.FieldFinSentiment, 0, &stubClassifier{
+		name:   "sentiment",
+		labels: []LabelScore{{Label: "negative", Score: 0.7}},
+	})
+	tpf.AddProcessor("toxicity", 0, &stubClassifier{
+		name: "toxicity",
+		err:  errors.New("boom"),
+	})
+	tpf.AddProcessor("emotion", 0, &stubClassifier{
+		name:   "emotion",
+		labels: []LabelScore{{Label: "joy", Score: 0.6}},
+	})
+
+	results, err := tpf.ProcessAll("some text")
+	if err == nil {
+		t.Errorf("Expected error from failing classifier")
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	message := &models.ProtoMessage{}
+	ApplyClassifications(message, results)
+
+	if len(message.Categories) != 2 ||
+		message.Categories[0] != "politics" ||
+		message.Categories[1] != "government" {
+		t.Errorf("Unexpected categories: %v", message.Categories)
+	}
+	if message.FinSentiment == nil || *message.FinSentiment != "negative" {
+		t.Errorf("Unexpected fin sentiment: %v", message.FinSentiment)
+	}
+
+	emotion, ok := message.Classifications["emotion"]
+	if !ok {
+		t.Fatalf("Expected generic classification for emotion")
+	}
+	if emotion.Classifier != "emotion" || emotion.ModelVersion != "v1" {
+		t.Errorf("Unexpected classification: %v", emotion)
+	}
+	if len(emotion.Labels) != 1 || emotion.Labels[0] != "joy" {
+		t.Errorf("Unexpected emotion labels: %v", emotion.Labels)
+	}
+	if _, ok := message.Classifications["toxicity"]; ok {
+		t.Errorf("Failed classifier should not be stored")
+	}
+}
+
+func TestTextProcessorFactory_Thresholds(t *testing.T) {
+	tpf := NewTextProcessorFactory()
+	tpf.AddProcessor(config.FieldCategories, 0.3, &stubClassifier{
+		name: "category",
+		labels: []LabelScore{
+			{Label: "economy, business and finance", Score: 0.6},
+			{Label: "politics", Score: 0.35},
+			{Label: "sport", Score: 0.05},
+		},
+	})
+	tpf.AddProcessor(config.FieldFinSentiment, 0.5, &stubClassifier{
+		name:   "sentiment",
+		labels: []LabelScore{{Label: "positive", Score: 0.4}},
+	})
+
+	results, err := tpf.ProcessAll("some text")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	message := &models.ProtoMessage{}
+	ApplyClassifications(message, results)
+
+	expected := []string{"economy", "business", "and", "finance", "politics"}
+	if len(message.Categories) != len(expected) {
+		t.Fatalf("Expected categories %v, got %v", expected, message.Categories)
+	}
+	for i, category := range expected {
+		if message.Categories[i] != category {
+			t.Errorf("Expected category %s at %d, got %s", category, i, message.Categories[i])
+		}
+	}
+
+	categories := message.Classifications[config.FieldCategories]
+	if len(categories.Labels) != 2 || len(categories.Scores) != 2 || categories.Scores[1] != 0.35 {
+		t.Errorf("Unexpected top-k classification: %v", categories)
+	}
+
+	if message.FinSentiment == nil || *message.FinSentiment != UnknownLabel {
+		t.Errorf("Expected low confidence sentiment to be unknown, got %v", message.FinSentiment)
+	}
+	sentiment := message.Classifications[config.FieldFinSentiment]
+	if len(sentiment.Scores) != 1 || sentiment.Scores[0] != 0.4 {
+		t.Errorf("Expected unknown label to keep the best score, got %v", sentiment.Scores)
+	}
+}
+
+func TestNewClassifier_UnknownType(t *testing.T) {
+	_, err := NewClassifier(config.ClassifierSpec{Name: "x", Type: "carrier-pigeon"})
+	if err == nil {
+		t.Errorf("Expected error for unknown classifier type")
+	}
+}
+
+func TestInitProcessors_FallbackLexicon(t *testing.T) {
+	_, err := InitProcessors(&config.AppConfig{
+		Classifiers: []config.ClassifierSpec{{
+			Name:                   "sentiment",
+			Type:                   config.ClassifierTypeRemote,
+			URL:                    "http://localhost:3100",
+			Field:                  config.FieldFinSentiment,
+			Fallback:               config.ClassifierTypeLexicon,
+			Lexicon:                filepath.Join(t.TempDir(), "missing.csv"),
+			CircuitBreakerFailures: config.DefaultCircuitBreakerFailures,
+		}},
+	}, nil)
+	if err == nil {
+		t.Error("Expected the fallback to load the configured lexicon")
+	}
+}
+
+func TestInitProcessors_GRPCClassifier(t *testing.T) {
+	server := mlclassifiertest.NewFakeServer(func(text string) []*classifierpb.ScoredLabel {
+		return []*classifierpb.ScoredLabel{{Label: "positive", Score: 0.9}}
+	})
+	defer server.Close()
+
+	tpf, err := InitProcessors(&config.AppConfig{
+		Classifiers: []config.ClassifierSpec{{
+			Name:  "sentiment",
+			Type:  config.ClassifierTypeRemote,
+			URL:   server.URL,
+			Field: config.FieldFinSentiment,
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, err := tpf.ProcessAll("to the moon")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].TopLabel() != "positive" {
+		t.Fatalf("Unexpected results: %v", results)
+	}
+	if results[0].ModelVersion != "fake-1" {
+		t.Errorf("Expected model version from the service, got %q", results[0].ModelVersion)
+	}
+}
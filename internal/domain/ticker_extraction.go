@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+)
+
+// Scores reported for the two kinds of mentions, so a min_score can drop
+// company names and keep only explicit cashtags.
+const (
+	cashtagScore = 1.0
+	aliasScore   = 0.7
+)
+
+// cashtagRegex matches $AAPL or $BRK.B style tags that are not part of a word
+// or an amount such as $100.
+var cashtagRegex = regexp.MustCompile(`(?:^|[^\w$])\$([A-Za-z][A-Za-z0-9]{0,5}(?:\.[A-Za-z]{1,2})?)\b`)
+
+// TickerExtractor finds the instruments a post talks about, from cashtags and
+// company names listed in a symbol reference file.
+type TickerExtractor struct {
+	name     string
+	symbols  map[string]bool
+	aliases  map[string]string
+	aliasRgx *regexp.Regexp
+}
+
+// NewTickerExtractor creates an extractor. With no symbols every cashtag is
+// accepted, otherwise only listed symbols are.
+func NewTickerExtractor(name string, symbols map[string][]string) *TickerExtractor {
+	te := &TickerExtractor{
+		name:    name,
+		symbols: make(map[string]bool, len(symbols)),
+		aliases: make(map[string]string),
+	}
+
+	var patterns []string
+	for symbol, aliases := range symbols {
+		symbol = strings.ToUpper(symbol)
+		te.symbols[symbol] = true
+		for _, alias := range aliases {
+			if _, ok := te.aliases[alias]; !ok {
+				te.aliases[alias] = symbol
+				patterns = append(patterns, regexp.QuoteMeta(alias))
+			}
+		}
+	}
+
+	if len(patterns) > 0 {
+		// Longest first so "Apple Inc" wins over "Apple".
+		sort.Slice(patterns, func(i, j int) bool {
+			if len(patterns[i]) != len(patterns[j]) {
+				return len(patterns[i]) > len(patterns[j])
+			}
+			return patterns[i] < patterns[j]
+		})
+		te.aliasRgx = regexp.MustCompile(`\b(?:` + strings.Join(patterns, "|") + `)\b`)
+	}
+
+	return te
+}
+
+func newTickerClassifier(spec config.ClassifierSpec) (Classifier, error) {
+	var symbols map[string][]string
+	if spec.Symbols != "" {
+		var err error
+		if symbols, err = LoadSymbols(spec.Symbols); err != nil {
+			return nil, fmt.Errorf("classifier %s: %w", spec.Name, err)
+		}
+	}
+	return NewTickerExtractor(spec.Name, symbols), nil
+}
+
+// LoadSymbols reads a reference file with one instrument per line: the symbol
+// followed by any number of company names, e.g. "AAPL,Apple,Apple Inc".
+func LoadSymbols(path string) (map[string][]string, error) {
+	f, err := os.Open(path) //nolint:gosec // path comes from configuration
+	if err != nil {
+		return nil, fmt.Errorf("opening symbols %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	symbols := make(map[string][]string)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return symbols, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading symbols %s: %w", path, err)
+		}
+
+		symbol := strings.ToUpper(strings.TrimSpace(record[0]))
+		if symbol == "" {
+			continue
+		}
+		for _, alias := range record[1:] {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				symbols[symbol] = append(symbols[symbol], alias)
+			}
+		}
+		if _, ok := symbols[symbol]; !ok {
+			symbols[symbol] = nil
+		}
+	}
+}
+
+func (te *TickerExtractor) Name() string {
+	return te.name
+}
+
+// Classify returns one label per ticker mentioned in text, cashtags first.
+func (te *TickerExtractor) Classify(text string) (*ClassificationResult, error) {
+	return &ClassificationResult{
+		Classifier: te.name,
+		Labels:     te.Extract(text),
+	}, nil
+}
+
+// Extract returns the normalized tickers mentioned in text.
+func (te *TickerExtractor) Extract(text string) []LabelScore {
+	var labels []LabelScore
+	seen := make(map[string]bool)
+
+	for _, match := range cashtagRegex.FindAllStringSubmatch(text, -1) {
+		ticker := strings.ToUpper(match[1])
+		if seen[ticker] || (len(te.symbols) > 0 && !te.symbols[ticker]) {
+			continue
+		}
+		seen[ticker] = true
+		labels = append(labels, LabelScore{Label: ticker, Score: cashtagScore})
+	}
+
+	if te.aliasRgx != nil {
+		for _, alias := range te.aliasRgx.FindAllString(text, -1) {
+			ticker := te.aliases[alias]
+			if seen[ticker] {
+				continue
+			}
+			seen[ticker] = true
+			labels = append(labels, LabelScore{Label: ticker, Score: aliasScore})
+		}
+	}
+
+	return labels
+}
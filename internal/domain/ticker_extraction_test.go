@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+func TestTickerExtractor_Extract(t *testing.T) {
+	te := NewTickerExtractor("tickers", map[string][]string{
+		"AAPL":  {"Apple", "Apple Inc"},
+		"BRK.B": {"Berkshire Hathaway"},
+		"TSLA":  {"Tesla"},
+		"NVDA":  nil,
+	})
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "Cashtags", text: "Loading up on $aapl and $NVDA today", expected: []string{"AAPL", "NVDA"}},
+		{name: "Class shares", text: "$BRK.B at highs", expected: []string{"BRK.B"}},
+		{name: "Unknown cashtag", text: "$HTTP is not a ticker", expected: nil},
+		{name: "Amounts", text: "Paid $100 for it", expected: nil},
+		{name: "Company names", text: "Apple Inc beat, Tesla missed", expected: []string{"AAPL", "TSLA"}},
+		{name: "Cashtag and name", text: "Apple earnings $AAPL", expected: []string{"AAPL"}},
+		{name: "Lowercase name", text: "an apple a day", expected: nil},
+		{name: "Inside a word", text: "Pineapple and US$TSLA", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			labels := te.Extract(tc.text)
+			if len(labels) != len(tc.expected) {
+				t.Fatalf("Expected %v, got %v", tc.expected, labels)
+			}
+			for i, ticker := range tc.expected {
+				if labels[i].Label != ticker {
+					t.Errorf("Expected %s at %d, got %s", ticker, i, labels[i].Label)
+				}
+			}
+		})
+	}
+}
+
+func TestTickerExtractor_Scores(t *testing.T) {
+	te := NewTickerExtractor("tickers", map[string][]string{"TSLA": {"Tesla"}, "AAPL": nil})
+
+	labels := te.Extract("$AAPL and Tesla")
+	if len(labels) != 2 || labels[0].Score <= labels[1].Score {
+		t.Errorf("Expected cashtags to score higher than names, got %v", labels)
+	}
+
+	result := &ClassificationResult{Field: "tickers", Labels: labels}
+	result.applyThreshold(cashtagScore)
+
+	message := &models.ProtoMessage{}
+	ApplyClassifications(message, []*ClassificationResult{result})
+	if len(message.Tickers) != 1 || message.Tickers[0] != "AAPL" {
+		t.Errorf("Expected only the cashtag to pass the threshold, got %v", message.Tickers)
+	}
+}
+
+func TestTickerExtractor_AnyCashtagWithoutSymbols(t *testing.T) {
+	te := NewTickerExtractor("tickers", nil)
+
+	labels := te.Extract("$GME to the moon")
+	if len(labels) != 1 || labels[0].Label != "GME" {
+		t.Errorf("Expected GME, got %v", labels)
+	}
+}
+
+func TestLoadSymbols(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "symbols.csv")
+	content := "# symbols\nmsft,Microsoft\nAAPL,Apple,Apple Inc\nSPY\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	symbols, err := LoadSymbols(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(symbols) != 3 {
+		t.Fatalf("Expected 3 symbols, got %v", symbols)
+	}
+	if len(symbols["AAPL"]) != 2 || symbols["MSFT"][0] != "Microsoft" {
+		t.Errorf("Unexpected aliases: %v", symbols)
+	}
+	if _, ok := symbols["SPY"]; !ok {
+		t.Errorf("Expected symbol without aliases to be kept")
+	}
+}
@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// Domain kinds reported for linked urls.
+const (
+	DomainKindNews      = "news"
+	DomainKindSocial    = "social"
+	DomainKindShortener = "shortener"
+	DomainKindOther     = "other"
+)
+
+// Where a link was found on the post.
+const (
+	LinkSourceFacet  = "facet"
+	LinkSourceEmbed  = "embed"
+	LinkSourceRecord = "record"
+)
+
+// defaultDomainKinds is used when no domains file is configured.
+var defaultDomainKinds = map[string][]string{
+	DomainKindNews: {
+		"apnews.com", "axios.com", "bbc.co.uk", "bbc.com", "bloomberg.com", "cnbc.com",
+		"cnn.com", "economist.com", "ft.com", "marketwatch.com", "npr.org", "nytimes.com",
+		"politico.com", "reuters.com", "theguardian.com", "washingtonpost.com", "wsj.com",
+		"finance.yahoo.com",
+	},
+	DomainKindSocial: {
+		"bsky.app", "facebook.com", "instagram.com", "linkedin.com", "mastodon.social",
+		"reddit.com", "threads.net", "tiktok.com", "twitter.com", "x.com", "youtube.com",
+	},
+	DomainKindShortener: {
+		"bit.ly", "buff.ly", "dlvr.it", "goo.gl", "ift.tt", "is.gd", "ow.ly", "t.co",
+		"tinyurl.com", "youtu.be",
+	},
+}
+
+// trackingParams are removed from query strings, together with any utm_* parameter.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "igshid": true, "_hsenc": true, "_hsmi": true,
+	"mkt_tok": true, "ref": true, "ref_src": true, "ref_url": true, "smid": true,
+	"cmpid": true, "si": true, "spm": true, "__twitter_impression": true,
+}
+
+// hostPrefixes are dropped so mobile and amp links count with their canonical host.
+var hostPrefixes = []string{"www.", "m.", "mobile.", "amp."}
+
+// URLExtractor gathers every link on a post, normalizes it and attributes its domain.
+type URLExtractor struct {
+	kinds map[string]string
+}
+
+// NewURLExtractor creates an extractor from kind -> domains. Subdomains of a listed
+// domain share its kind.
+func NewURLExtractor(domainKinds map[string][]string) *URLExtractor {
+	kinds := make(map[string]string)
+	for kind, domains := range domainKinds {
+		for _, domain := range domains {
+			kinds[strings.ToLower(domain)] = kind
+		}
+	}
+	return &URLExtractor{kinds: kinds}
+}
+
+// LoadDomainKinds reads a YAML/JSON file mapping each kind to its domains:
+//
+//	news: [reuters.com, apnews.com]
+//	shortener: [bit.ly]
+func LoadDomainKinds(path string) (map[string][]string, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading domains file %s: %w", path, err)
+	}
+
+	domainKinds := make(map[string][]string)
+	for _, kind := range v.AllKeys() {
+		domainKinds[kind] = v.GetStringSlice(kind)
+	}
+	return domainKinds, nil
+}
+
+func (ue *URLExtractor) Name() string {
+	return "URLExtractor"
+}
+
+// Process stores the normalized links of the message on it.
+func (ue *URLExtractor) Process(message *models.ProtoMessage) error {
+	message.Links = ue.Extract(message)
+	return nil
+}
+
+// Extract returns the unique normalized links of a message in the order found.
+func (ue *URLExtractor) Extract(message *models.ProtoMessage) []*models.LinkedUrl {
+	if message.Commit == nil || message.Commit.Record == nil {
+		return nil
+	}
+	record := message.Commit.Record
+
+	var links []*models.LinkedUrl
+	seen := make(map[string]*models.LinkedUrl)
+	add := func(raw, source string, title *string) {
+		normalized, domain, err := NormalizeURL(raw)
+		if err != nil {
+			return
+		}
+		if existing, ok := seen[normalized]; ok {
+			if existing.Title == nil && title != nil && *title != "" {
+				existing.Title = title
+			}
+			return
+		}
+
+		link := &models.LinkedUrl{
+			Url:    normalized,
+			Domain: domain,
+			Kind:   ue.Kind(domain),
+			Source: source,
+		}
+		if title != nil && *title != "" {
+			link.Title = title
+		}
+		seen[normalized] = link
+		links = append(links, link)
+	}
+
+	for _, facet := range record.Facets {
+		for _, feature := range facet.Features {
+			if feature.Uri != nil {
+				add(*feature.Uri, LinkSourceFacet, nil)
+			}
+			if feature.Link != nil {
+				add(feature.Link.Uri, LinkSourceFacet, nil)
+			}
+		}
+	}
+
+	if embed := record.Embed; embed != nil {
+		if embed.External != nil && embed.External.Uri != nil {
+			add(*embed.External.Uri, LinkSourceEmbed, embed.External.Title)
+		}
+		if embed.Media != nil && embed.Media.External != nil && embed.Media.External.Uri != nil {
+			add(*embed.Media.External.Uri, LinkSourceEmbed, embed.Media.External.Title)
+		}
+	}
+
+	if record.External != nil && record.External.Uri != nil {
+		add(*record.External.Uri, LinkSourceRecord, record.External.Title)
+	}
+
+	return links
+}
+
+// Kind returns the configured kind of domain or of its closest listed parent.
+func (ue *URLExtractor) Kind(domain string) string {
+	for d := domain; d != ""; {
+		if kind, ok := ue.kinds[d]; ok {
+			return kind
+		}
+		dot := strings.IndexByte(d, '.')
+		if dot < 0 {
+			break
+		}
+		d = d[dot+1:]
+	}
+	return DomainKindOther
+}
+
+// NormalizeURL lowercases the scheme and host, drops www/mobile/amp host prefixes,
+// default ports, fragments, trailing slashes and tracking parameters, and sorts
+// the remaining query parameters. It returns the url and its normalized host.
+func NormalizeURL(raw string) (string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", "", err
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("unsupported url scheme: %q", u.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", "", fmt.Errorf("url has no host: %q", raw)
+	}
+	for _, prefix := range hostPrefixes {
+		if strings.HasPrefix(host, prefix) && strings.Count(host, ".") > 1 {
+			host = strings.TrimPrefix(host, prefix)
+			break
+		}
+	}
+
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	u.Host = host
+	if port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	}
+
+	query := u.Query()
+	for key := range query {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	// Encode sorts by key, which makes equivalent urls compare equal.
+	u.RawQuery = query.Encode()
+
+	if u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	}
+	u.RawPath = ""
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+
+	return u.String(), host, nil
+}
+
+// TopDomains counts links per domain, most linked first.
+func TopDomains(messages []*models.ProtoMessage, limit int) []DomainCount {
+	counts := make(map[string]int)
+	for _, message := range messages {
+		for _, link := range message.Links {
+			counts[link.Domain]++
+		}
+	}
+
+	top := make([]DomainCount, 0, len(counts))
+	for domain, count := range counts {
+		top = append(top, DomainCount{Domain: domain, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Domain < top[j].Domain
+	})
+
+	if limit > 0 && len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+// DomainCount is the number of links pointing at a domain.
+type DomainCount struct {
+	Domain string
+	Count  int
+}
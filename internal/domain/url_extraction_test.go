@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		raw      string
+		expected string
+		domain   string
+	}{
+		{
+			name:     "Tracking parameters",
+			raw:      "https://www.reuters.com/markets/story/?utm_source=bsky&utm_medium=social&fbclid=abc",
+			expected: "https://reuters.com/markets/story",
+			domain:   "reuters.com",
+		},
+		{
+			name:     "Sorted query",
+			raw:      "HTTPS://Example.COM:443/a?b=2&a=1#section",
+			expected: "https://example.com/a?a=1&b=2",
+			domain:   "example.com",
+		},
+		{
+			name:     "Mobile host",
+			raw:      "https://m.youtube.com/watch?v=xyz&si=share",
+			expected: "https://youtube.com/watch?v=xyz",
+			domain:   "youtube.com",
+		},
+		{
+			name:     "Keeps bare prefix domains",
+			raw:      "http://m.co:8080/",
+			expected: "http://m.co:8080/",
+			domain:   "m.co",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized, domain, err := NormalizeURL(tc.raw)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if normalized != tc.expected {
+				t.Errorf("Expected url %q, got %q", tc.expected, normalized)
+			}
+			if domain != tc.domain {
+				t.Errorf("Expected domain %q, got %q", tc.domain, domain)
+			}
+		})
+	}
+
+	for _, raw := range []string{"mailto:someone@example.com", "at://did:plc:abc/app.bsky.feed.post/1", "/relative"} {
+		if _, _, err := NormalizeURL(raw); err == nil {
+			t.Errorf("Expected an error for %q", raw)
+		}
+	}
+}
+
+func TestURLExtractor_Extract(t *testing.T) {
+	facetURL := "https://www.reuters.com/markets/story?utm_source=bsky"
+	cardURL := "https://reuters.com/markets/story/"
+	cardTitle := "Markets rally"
+	shortURL := "https://bit.ly/3abc"
+
+	message := &models.ProtoMessage{
+		Commit: &models.Commit{
+			Record: &models.Record{
+				Facets: []*models.Facet{
+					{Features: []*models.Feature{{Uri: &facetURL}}},
+					{Features: []*models.Feature{{Link: &models.Link{Uri: shortURL}}}},
+				},
+				Embed: &models.Embed{
+					External: &models.EmbedExternal{Uri: &cardURL, Title: &cardTitle},
+				},
+			},
+		},
+	}
+
+	ue := NewURLExtractor(defaultDomainKinds)
+	if err := ue.Process(message); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(message.Links) != 2 {
+		t.Fatalf("Expected 2 links, got %v", message.Links)
+	}
+
+	article := message.Links[0]
+	if article.Url != "https://reuters.com/markets/story" || article.Kind != DomainKindNews {
+		t.Errorf("Unexpected article link: %v", article)
+	}
+	if article.Source != LinkSourceFacet {
+		t.Errorf("Expected source %q, got %q", LinkSourceFacet, article.Source)
+	}
+	if article.Title == nil || *article.Title != cardTitle {
+		t.Errorf("Expected the link card title on the deduplicated link, got %v", article.Title)
+	}
+
+	if message.Links[1].Kind != DomainKindShortener {
+		t.Errorf("Expected %q, got %q", DomainKindShortener, message.Links[1].Kind)
+	}
+}
+
+func TestURLExtractor_Kind(t *testing.T) {
+	ue := NewURLExtractor(map[string][]string{"news": {"bbc.co.uk"}})
+
+	testCases := map[string]string{
+		"bbc.co.uk":      "news",
+		"news.bbc.co.uk": "news",
+		"co.uk":          DomainKindOther,
+		"example.com":    DomainKindOther,
+	}
+	for domain, expected := range testCases {
+		if kind := ue.Kind(domain); kind != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, domain, kind)
+		}
+	}
+}
+
+func TestLoadDomainKinds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "domains.yaml")
+	content := "news:\n  - reuters.com\nshortener:\n  - bit.ly\n  - t.co\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	domainKinds, err := LoadDomainKinds(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(domainKinds["news"]) != 1 || len(domainKinds["shortener"]) != 2 {
+		t.Errorf("Unexpected domain kinds: %v", domainKinds)
+	}
+}
+
+func TestTopDomains(t *testing.T) {
+	messages := []*models.ProtoMessage{
+		{Links: []*models.LinkedUrl{{Domain: "reuters.com"}, {Domain: "bit.ly"}}},
+		{Links: []*models.LinkedUrl{{Domain: "reuters.com"}}},
+		{},
+	}
+
+	top := TopDomains(messages, 1)
+	if len(top) != 1 || top[0].Domain != "reuters.com" || top[0].Count != 2 {
+		t.Errorf("Unexpected top domains: %v", top)
+	}
+}
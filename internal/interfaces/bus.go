@@ -0,0 +1,23 @@
+package interfaces
+
+// BusMessage is a message delivered by a Bus.
+type BusMessage interface {
+	Topic() string
+	Payload() []byte
+	// Ack confirms the message was processed, unacknowledged messages are delivered
+	// again.
+	Ack()
+}
+
+// Bus is a message broker posts, metrics and dead letters travel through. Topics
+// are "/" separated, as in MQTT, and mapped by each backend to its own naming.
+type Bus interface {
+	// Publish sends data to topic and waits for the broker to accept it.
+	Publish(topic string, data []byte) error
+	// Subscribe delivers the messages of topic to handler until Unsubscribe is called.
+	// Subscribers in the same consumer group share the messages of a topic.
+	Subscribe(topic string, handler func(BusMessage)) error
+	Unsubscribe(topic string) error
+	IsConnected() bool
+	Close() error
+}
@@ -0,0 +1,10 @@
+package interfaces
+
+import "stockseer.ai/blueksy-firehose/internal/models"
+
+// MessageClient defines the message bus operations that AppContext needs to expose.
+type MessageClient interface {
+	IsConnected() bool
+	PublishMessage(msg *models.ProtoMessage) error
+	ConsumeMessages() error
+}
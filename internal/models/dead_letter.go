@@ -0,0 +1,67 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Stages of the server pipeline a message can fail in.
+const (
+	DeadLetterStageParse    = "parse"
+	DeadLetterStageClassify = "classify"
+	DeadLetterStageStore    = "store"
+)
+
+// Sinks dead letters can be written to.
+const (
+	DeadLetterSinkMQTT  = "mqtt"
+	DeadLetterSinkMongo = "mongo"
+)
+
+// DeadLetter is a message the server failed to process, kept with the payload as it
+// was received so it can be replayed once the cause is fixed. Attempts counts the
+// attempts of every time it failed, Replays the times it was replayed.
+type DeadLetter struct {
+	ID       string    `json:"id"        bson:"_id"`
+	Stage    string    `json:"stage"     bson:"stage"`
+	Reason   string    `json:"reason"    bson:"reason"`
+	Attempts int       `json:"attempts"  bson:"attempts"`
+	Replays  int       `json:"replays"   bson:"replays"`
+	Topic    string    `json:"topic"     bson:"topic"`
+	Payload  []byte    `json:"payload"   bson:"payload"`
+	FailedAt time.Time `json:"failed_at" bson:"failed_at"`
+}
+
+// NewDeadLetter creates the dead letter of a payload received on topic. The id is
+// derived from the payload, so a message failing again replaces its earlier entry.
+func NewDeadLetter(topic string, payload []byte, stage string, attempts int, err error) *DeadLetter {
+	sum := sha256.Sum256(payload)
+
+	return &DeadLetter{
+		ID:       hex.EncodeToString(sum[:]),
+		Stage:    stage,
+		Reason:   err.Error(),
+		Attempts: attempts,
+		Topic:    topic,
+		Payload:  payload,
+		FailedAt: time.Now().UTC(),
+	}
+}
+
+// FailedAgain carries the attempts and replays of earlier, the letter of the same
+// message before it was replayed, over to dl.
+func (dl *DeadLetter) FailedAgain(earlier *DeadLetter) {
+	dl.Attempts += earlier.Attempts
+	dl.Replays = earlier.Replays + 1
+}
+
+// ToJSON marshals the DeadLetter struct to a JSON string.
+func (dl *DeadLetter) ToJSON() (string, error) {
+	jsonData, err := json.Marshal(dl)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
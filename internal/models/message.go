@@ -90,8 +90,28 @@ func FromJSONMap(msg proto.Message, data map[string]interface{}) error {
 	return FromJSON(msg, string(jsonStr))
 }
 
-// WithDateTime adds a DateTime field to the map if TimeUs is present.
-// TimeUs is in microseconds since the epoch.
+// MaxCreatedAtSkew is how far ahead of the time a message was received the createdAt
+// of its record may be. Clients set createdAt from their own clocks, a later one is
+// taken as a wrong clock rather than a post from the future.
+const MaxCreatedAtSkew = 5 * time.Minute
+
+// minCreatedAt is the earliest createdAt taken as the creation time of a post,
+// earlier ones are placeholders such as the zero time or the Unix epoch.
+var minCreatedAt = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// createdAtLayouts are the formats createdAt is parsed in, RFC 3339 as the lexicon
+// requires and the variants clients are seen to send.
+var createdAtLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// WithDateTime converts the message to a map with the time it was received in the
+// datetime field and the time its record was created in the created_at field, as
+// time.Time values so they are stored as dates.
 func (m *ProtoMessage) WithDateTime() (*map[string]interface{}, error) {
 	// Convert the message to a map first
 	data, err := ToJSONMap(m)
@@ -99,12 +119,59 @@ func (m *ProtoMessage) WithDateTime() (*map[string]interface{}, error) {
 		return nil, fmt.Errorf("failed to convert message to map: %w", err)
 	}
 
-	// Add DateTime field if TimeUs is present
-	data["datetime"] = time.UnixMicro(m.TimeUs).String()
+	data["datetime"] = m.ReceivedAt()
+	data["created_at"] = m.CreatedAt()
 
 	return &data, nil
 }
 
+// ReceivedAt returns the time the Jetstream received the message, from TimeUs.
+func (m *ProtoMessage) ReceivedAt() time.Time {
+	return time.UnixMicro(m.GetTimeUs()).UTC()
+}
+
+// CreatedAt returns the time the record of the message was created, see CreatedAt.
+func (m *ProtoMessage) CreatedAt() time.Time {
+	return CreatedAt(m.GetCommit().GetRecord().GetCreatedAt(), m.ReceivedAt())
+}
+
+// CreatedAt parses the createdAt of a record received at receivedAt. A missing or
+// malformed createdAt, one before 2000 or more than MaxCreatedAtSkew after receivedAt
+// is not trusted and receivedAt is returned instead.
+func CreatedAt(value string, receivedAt time.Time) time.Time {
+	for _, layout := range createdAtLayouts {
+		createdAt, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		if createdAt.Before(minCreatedAt) || createdAt.After(receivedAt.Add(MaxCreatedAtSkew)) {
+			return receivedAt
+		}
+		return createdAt.UTC()
+	}
+	return receivedAt
+}
+
+// URI returns the AT URI of the record the message commits,
+// at://<did>/<collection>/<rkey>, or an empty string when the message has none.
+func (m *ProtoMessage) URI() string {
+	commit := m.GetCommit()
+	if m.GetDid() == "" || commit.GetCollection() == "" || commit.GetRkey() == "" {
+		return ""
+	}
+	return "at://" + m.GetDid() + "/" + commit.GetCollection() + "/" + commit.GetRkey()
+}
+
+// DocumentID returns the id a message is stored under: its AT URI, or the CID of its
+// record when it has no URI. The same post received twice gets the same id, so it is
+// stored once. Messages with neither get an empty id.
+func (m *ProtoMessage) DocumentID() string {
+	if uri := m.URI(); uri != "" {
+		return uri
+	}
+	return m.GetCommit().GetCid()
+}
+
 // Implement JSONMessage interface for all protobuf messages.
 func (m *ProtoMessage) ToJSON() (string, error) {
 	return ToJSON(m)
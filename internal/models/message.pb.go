@@ -1692,17 +1692,21 @@ func (x *Commit) GetCid() string {
 }
 
 type ProtoMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Did           string                 `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
-	TimeUs        int64                  `protobuf:"varint,2,opt,name=time_us,json=timeUs,proto3" json:"time_us,omitempty"`
-	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
-	Commit        *Commit                `protobuf:"bytes,4,opt,name=commit,proto3" json:"commit,omitempty"`
-	Categories    []string               `protobuf:"bytes,5,rep,name=categories,proto3" json:"categories,omitempty"`
-	FinSentiment  *string                `protobuf:"bytes,6,opt,name=fin_sentiment,json=finSentiment,proto3,oneof" json:"fin_sentiment,omitempty"`
-	Account       *Account               `protobuf:"bytes,7,opt,name=account,proto3,oneof" json:"account,omitempty"`
-	Identity      *Identity              `protobuf:"bytes,8,opt,name=identity,proto3,oneof" json:"identity,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState     `protogen:"open.v1"`
+	Did             string                     `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
+	TimeUs          int64                      `protobuf:"varint,2,opt,name=time_us,json=timeUs,proto3" json:"time_us,omitempty"`
+	Kind            string                     `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Commit          *Commit                    `protobuf:"bytes,4,opt,name=commit,proto3" json:"commit,omitempty"`
+	Categories      []string                   `protobuf:"bytes,5,rep,name=categories,proto3" json:"categories,omitempty"`
+	FinSentiment    *string                    `protobuf:"bytes,6,opt,name=fin_sentiment,json=finSentiment,proto3,oneof" json:"fin_sentiment,omitempty"`
+	Account         *Account                   `protobuf:"bytes,7,opt,name=account,proto3,oneof" json:"account,omitempty"`
+	Identity        *Identity                  `protobuf:"bytes,8,opt,name=identity,proto3,oneof" json:"identity,omitempty"`
+	Classifications map[string]*Classification `protobuf:"bytes,9,rep,name=classifications,proto3" json:"classifications,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tickers         []string                   `protobuf:"bytes,10,rep,name=tickers,proto3" json:"tickers,omitempty"`
+	Links           []*LinkedUrl               `protobuf:"bytes,11,rep,name=links,proto3" json:"links,omitempty"`
+	KeyPhrases      []*KeyPhrase               `protobuf:"bytes,12,rep,name=key_phrases,json=keyPhrases,proto3" json:"key_phrases,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *ProtoMessage) Reset() {
@@ -1791,6 +1795,240 @@ func (x *ProtoMessage) GetIdentity() *Identity {
 	return nil
 }
 
+func (x *ProtoMessage) GetClassifications() map[string]*Classification {
+	if x != nil {
+		return x.Classifications
+	}
+	return nil
+}
+
+func (x *ProtoMessage) GetTickers() []string {
+	if x != nil {
+		return x.Tickers
+	}
+	return nil
+}
+
+func (x *ProtoMessage) GetLinks() []*LinkedUrl {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *ProtoMessage) GetKeyPhrases() []*KeyPhrase {
+	if x != nil {
+		return x.KeyPhrases
+	}
+	return nil
+}
+
+// LinkedUrl is a normalized link found in a post's facets or embeds.
+type LinkedUrl struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`     // news, social, shortener, ... or other
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"` // facet, embed or record
+	Title         *string                `protobuf:"bytes,5,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LinkedUrl) Reset() {
+	*x = LinkedUrl{}
+	mi := &file_internal_models_message_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LinkedUrl) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LinkedUrl) ProtoMessage() {}
+
+func (x *LinkedUrl) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_models_message_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LinkedUrl.ProtoReflect.Descriptor instead.
+func (*LinkedUrl) Descriptor() ([]byte, []int) {
+	return file_internal_models_message_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *LinkedUrl) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *LinkedUrl) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *LinkedUrl) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *LinkedUrl) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *LinkedUrl) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+type KeyPhrase struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phrase        string                 `protobuf:"bytes,1,opt,name=phrase,proto3" json:"phrase,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"` // tf-idf against the rolling background corpus
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyPhrase) Reset() {
+	*x = KeyPhrase{}
+	mi := &file_internal_models_message_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyPhrase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyPhrase) ProtoMessage() {}
+
+func (x *KeyPhrase) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_models_message_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyPhrase.ProtoReflect.Descriptor instead.
+func (*KeyPhrase) Descriptor() ([]byte, []int) {
+	return file_internal_models_message_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *KeyPhrase) GetPhrase() string {
+	if x != nil {
+		return x.Phrase
+	}
+	return ""
+}
+
+func (x *KeyPhrase) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// Classification is the structured output of one configured classifier.
+type Classification struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Classifier    string                 `protobuf:"bytes,1,opt,name=classifier,proto3" json:"classifier,omitempty"`
+	Labels        []string               `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty"`
+	ModelVersion  string                 `protobuf:"bytes,3,opt,name=model_version,json=modelVersion,proto3" json:"model_version,omitempty"`
+	LatencyUs     int64                  `protobuf:"varint,4,opt,name=latency_us,json=latencyUs,proto3" json:"latency_us,omitempty"`
+	Scores        []float64              `protobuf:"fixed64,5,rep,packed,name=scores,proto3" json:"scores,omitempty"` // aligned with labels
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Classification) Reset() {
+	*x = Classification{}
+	mi := &file_internal_models_message_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Classification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Classification) ProtoMessage() {}
+
+func (x *Classification) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_models_message_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Classification.ProtoReflect.Descriptor instead.
+func (*Classification) Descriptor() ([]byte, []int) {
+	return file_internal_models_message_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Classification) GetClassifier() string {
+	if x != nil {
+		return x.Classifier
+	}
+	return ""
+}
+
+func (x *Classification) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Classification) GetModelVersion() string {
+	if x != nil {
+		return x.ModelVersion
+	}
+	return ""
+}
+
+func (x *Classification) GetLatencyUs() int64 {
+	if x != nil {
+		return x.LatencyUs
+	}
+	return 0
+}
+
+func (x *Classification) GetScores() []float64 {
+	if x != nil {
+		return x.Scores
+	}
+	return nil
+}
+
 type Account struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Active        bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
@@ -1804,7 +2042,7 @@ type Account struct {
 
 func (x *Account) Reset() {
 	*x = Account{}
-	mi := &file_internal_models_message_proto_msgTypes[22]
+	mi := &file_internal_models_message_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1816,7 +2054,7 @@ func (x *Account) String() string {
 func (*Account) ProtoMessage() {}
 
 func (x *Account) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_models_message_proto_msgTypes[22]
+	mi := &file_internal_models_message_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1829,7 +2067,7 @@ func (x *Account) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Account.ProtoReflect.Descriptor instead.
 func (*Account) Descriptor() ([]byte, []int) {
-	return file_internal_models_message_proto_rawDescGZIP(), []int{22}
+	return file_internal_models_message_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *Account) GetActive() bool {
@@ -2105,7 +2343,7 @@ const file_internal_models_message_proto_rawDesc = "" +
 	"collection\x12\x12\n" +
 	"\x04rkey\x18\x04 \x01(\tR\x04rkey\x12D\n" +
 	"\x06record\x18\x05 \x01(\v2,.stockseer.ai.blueksy.firehose.models.RecordR\x06record\x12\x10\n" +
-	"\x03cid\x18\x06 \x01(\tR\x03cid\"\xa7\x03\n" +
+	"\x03cid\x18\x06 \x01(\tR\x03cid\"\xc7\x06\n" +
 	"\fProtoMessage\x12\x10\n" +
 	"\x03did\x18\x01 \x01(\tR\x03did\x12\x17\n" +
 	"\atime_us\x18\x02 \x01(\x03R\x06timeUs\x12\x12\n" +
@@ -2116,11 +2354,39 @@ const file_internal_models_message_proto_rawDesc = "" +
 	"categories\x12(\n" +
 	"\rfin_sentiment\x18\x06 \x01(\tH\x00R\ffinSentiment\x88\x01\x01\x12L\n" +
 	"\aaccount\x18\a \x01(\v2-.stockseer.ai.blueksy.firehose.models.AccountH\x01R\aaccount\x88\x01\x01\x12O\n" +
-	"\bidentity\x18\b \x01(\v2..stockseer.ai.blueksy.firehose.models.IdentityH\x02R\bidentity\x88\x01\x01B\x10\n" +
+	"\bidentity\x18\b \x01(\v2..stockseer.ai.blueksy.firehose.models.IdentityH\x02R\bidentity\x88\x01\x01\x12q\n" +
+	"\x0fclassifications\x18\t \x03(\v2G.stockseer.ai.blueksy.firehose.models.ProtoMessage.ClassificationsEntryR\x0fclassifications\x12\x18\n" +
+	"\atickers\x18\n" +
+	" \x03(\tR\atickers\x12E\n" +
+	"\x05links\x18\v \x03(\v2/.stockseer.ai.blueksy.firehose.models.LinkedUrlR\x05links\x12P\n" +
+	"\vkey_phrases\x18\f \x03(\v2/.stockseer.ai.blueksy.firehose.models.KeyPhraseR\n" +
+	"keyPhrases\x1ax\n" +
+	"\x14ClassificationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12J\n" +
+	"\x05value\x18\x02 \x01(\v24.stockseer.ai.blueksy.firehose.models.ClassificationR\x05value:\x028\x01B\x10\n" +
 	"\x0e_fin_sentimentB\n" +
 	"\n" +
 	"\b_accountB\v\n" +
-	"\t_identity\"\x81\x01\n" +
+	"\t_identity\"\x86\x01\n" +
+	"\tLinkedUrl\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x19\n" +
+	"\x05title\x18\x05 \x01(\tH\x00R\x05title\x88\x01\x01B\b\n" +
+	"\x06_title\"9\n" +
+	"\tKeyPhrase\x12\x16\n" +
+	"\x06phrase\x18\x01 \x01(\tR\x06phrase\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\"\xa4\x01\n" +
+	"\x0eClassification\x12\x1e\n" +
+	"\n" +
+	"classifier\x18\x01 \x01(\tR\n" +
+	"classifier\x12\x16\n" +
+	"\x06labels\x18\x02 \x03(\tR\x06labels\x12#\n" +
+	"\rmodel_version\x18\x03 \x01(\tR\fmodelVersion\x12\x1d\n" +
+	"\n" +
+	"latency_us\x18\x04 \x01(\x03R\tlatencyUs\x12\x16\n" +
+	"\x06scores\x18\x05 \x03(\x01R\x06scores\"\x81\x01\n" +
 	"\aAccount\x12\x16\n" +
 	"\x06active\x18\x01 \x01(\bR\x06active\x12\x10\n" +
 	"\x03did\x18\x02 \x01(\tR\x03did\x12\x10\n" +
@@ -2141,33 +2407,37 @@ func file_internal_models_message_proto_rawDescGZIP() []byte {
 	return file_internal_models_message_proto_rawDescData
 }
 
-var file_internal_models_message_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_internal_models_message_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_internal_models_message_proto_goTypes = []any{
-	(*Label)(nil),         // 0: stockseer.ai.blueksy.firehose.models.Label
-	(*Value)(nil),         // 1: stockseer.ai.blueksy.firehose.models.Value
-	(*Entity)(nil),        // 2: stockseer.ai.blueksy.firehose.models.Entity
-	(*Identity)(nil),      // 3: stockseer.ai.blueksy.firehose.models.Identity
-	(*Facet)(nil),         // 4: stockseer.ai.blueksy.firehose.models.Facet
-	(*Feature)(nil),       // 5: stockseer.ai.blueksy.firehose.models.Feature
-	(*Link)(nil),          // 6: stockseer.ai.blueksy.firehose.models.Link
-	(*Index)(nil),         // 7: stockseer.ai.blueksy.firehose.models.Index
-	(*Embed)(nil),         // 8: stockseer.ai.blueksy.firehose.models.Embed
-	(*EmbedVideo)(nil),    // 9: stockseer.ai.blueksy.firehose.models.EmbedVideo
-	(*EmbedRecord)(nil),   // 10: stockseer.ai.blueksy.firehose.models.EmbedRecord
-	(*EmbedMedia)(nil),    // 11: stockseer.ai.blueksy.firehose.models.EmbedMedia
-	(*EmbedExternal)(nil), // 12: stockseer.ai.blueksy.firehose.models.EmbedExternal
-	(*EmbedImage)(nil),    // 13: stockseer.ai.blueksy.firehose.models.EmbedImage
-	(*AspectRatio)(nil),   // 14: stockseer.ai.blueksy.firehose.models.AspectRatio
-	(*Image)(nil),         // 15: stockseer.ai.blueksy.firehose.models.Image
-	(*Blob)(nil),          // 16: stockseer.ai.blueksy.firehose.models.Blob
-	(*Ref)(nil),           // 17: stockseer.ai.blueksy.firehose.models.Ref
-	(*Reply)(nil),         // 18: stockseer.ai.blueksy.firehose.models.Reply
-	(*Record)(nil),        // 19: stockseer.ai.blueksy.firehose.models.Record
-	(*Commit)(nil),        // 20: stockseer.ai.blueksy.firehose.models.Commit
-	(*ProtoMessage)(nil),  // 21: stockseer.ai.blueksy.firehose.models.ProtoMessage
-	(*Account)(nil),       // 22: stockseer.ai.blueksy.firehose.models.Account
-	nil,                   // 23: stockseer.ai.blueksy.firehose.models.Embed.DataEntry
-	nil,                   // 24: stockseer.ai.blueksy.firehose.models.EmbedImage.DataEntry
+	(*Label)(nil),          // 0: stockseer.ai.blueksy.firehose.models.Label
+	(*Value)(nil),          // 1: stockseer.ai.blueksy.firehose.models.Value
+	(*Entity)(nil),         // 2: stockseer.ai.blueksy.firehose.models.Entity
+	(*Identity)(nil),       // 3: stockseer.ai.blueksy.firehose.models.Identity
+	(*Facet)(nil),          // 4: stockseer.ai.blueksy.firehose.models.Facet
+	(*Feature)(nil),        // 5: stockseer.ai.blueksy.firehose.models.Feature
+	(*Link)(nil),           // 6: stockseer.ai.blueksy.firehose.models.Link
+	(*Index)(nil),          // 7: stockseer.ai.blueksy.firehose.models.Index
+	(*Embed)(nil),          // 8: stockseer.ai.blueksy.firehose.models.Embed
+	(*EmbedVideo)(nil),     // 9: stockseer.ai.blueksy.firehose.models.EmbedVideo
+	(*EmbedRecord)(nil),    // 10: stockseer.ai.blueksy.firehose.models.EmbedRecord
+	(*EmbedMedia)(nil),     // 11: stockseer.ai.blueksy.firehose.models.EmbedMedia
+	(*EmbedExternal)(nil),  // 12: stockseer.ai.blueksy.firehose.models.EmbedExternal
+	(*EmbedImage)(nil),     // 13: stockseer.ai.blueksy.firehose.models.EmbedImage
+	(*AspectRatio)(nil),    // 14: stockseer.ai.blueksy.firehose.models.AspectRatio
+	(*Image)(nil),          // 15: stockseer.ai.blueksy.firehose.models.Image
+	(*Blob)(nil),           // 16: stockseer.ai.blueksy.firehose.models.Blob
+	(*Ref)(nil),            // 17: stockseer.ai.blueksy.firehose.models.Ref
+	(*Reply)(nil),          // 18: stockseer.ai.blueksy.firehose.models.Reply
+	(*Record)(nil),         // 19: stockseer.ai.blueksy.firehose.models.Record
+	(*Commit)(nil),         // 20: stockseer.ai.blueksy.firehose.models.Commit
+	(*ProtoMessage)(nil),   // 21: stockseer.ai.blueksy.firehose.models.ProtoMessage
+	(*LinkedUrl)(nil),      // 22: stockseer.ai.blueksy.firehose.models.LinkedUrl
+	(*KeyPhrase)(nil),      // 23: stockseer.ai.blueksy.firehose.models.KeyPhrase
+	(*Classification)(nil), // 24: stockseer.ai.blueksy.firehose.models.Classification
+	(*Account)(nil),        // 25: stockseer.ai.blueksy.firehose.models.Account
+	nil,                    // 26: stockseer.ai.blueksy.firehose.models.Embed.DataEntry
+	nil,                    // 27: stockseer.ai.blueksy.firehose.models.EmbedImage.DataEntry
+	nil,                    // 28: stockseer.ai.blueksy.firehose.models.ProtoMessage.ClassificationsEntry
 }
 var file_internal_models_message_proto_depIdxs = []int32{
 	1,  // 0: stockseer.ai.blueksy.firehose.models.Label.values:type_name -> stockseer.ai.blueksy.firehose.models.Value
@@ -2181,7 +2451,7 @@ var file_internal_models_message_proto_depIdxs = []int32{
 	9,  // 8: stockseer.ai.blueksy.firehose.models.Embed.video:type_name -> stockseer.ai.blueksy.firehose.models.EmbedVideo
 	14, // 9: stockseer.ai.blueksy.firehose.models.Embed.aspect_ratio:type_name -> stockseer.ai.blueksy.firehose.models.AspectRatio
 	10, // 10: stockseer.ai.blueksy.firehose.models.Embed.record:type_name -> stockseer.ai.blueksy.firehose.models.EmbedRecord
-	23, // 11: stockseer.ai.blueksy.firehose.models.Embed.data:type_name -> stockseer.ai.blueksy.firehose.models.Embed.DataEntry
+	26, // 11: stockseer.ai.blueksy.firehose.models.Embed.data:type_name -> stockseer.ai.blueksy.firehose.models.Embed.DataEntry
 	16, // 12: stockseer.ai.blueksy.firehose.models.EmbedVideo.ref:type_name -> stockseer.ai.blueksy.firehose.models.Blob
 	19, // 13: stockseer.ai.blueksy.firehose.models.EmbedRecord.record:type_name -> stockseer.ai.blueksy.firehose.models.Record
 	12, // 14: stockseer.ai.blueksy.firehose.models.EmbedMedia.external:type_name -> stockseer.ai.blueksy.firehose.models.EmbedExternal
@@ -2191,7 +2461,7 @@ var file_internal_models_message_proto_depIdxs = []int32{
 	15, // 18: stockseer.ai.blueksy.firehose.models.EmbedExternal.thumb:type_name -> stockseer.ai.blueksy.firehose.models.Image
 	15, // 19: stockseer.ai.blueksy.firehose.models.EmbedImage.image:type_name -> stockseer.ai.blueksy.firehose.models.Image
 	14, // 20: stockseer.ai.blueksy.firehose.models.EmbedImage.aspect_ratio:type_name -> stockseer.ai.blueksy.firehose.models.AspectRatio
-	24, // 21: stockseer.ai.blueksy.firehose.models.EmbedImage.data:type_name -> stockseer.ai.blueksy.firehose.models.EmbedImage.DataEntry
+	27, // 21: stockseer.ai.blueksy.firehose.models.EmbedImage.data:type_name -> stockseer.ai.blueksy.firehose.models.EmbedImage.DataEntry
 	16, // 22: stockseer.ai.blueksy.firehose.models.Image.ref:type_name -> stockseer.ai.blueksy.firehose.models.Blob
 	14, // 23: stockseer.ai.blueksy.firehose.models.Image.aspect_ratio:type_name -> stockseer.ai.blueksy.firehose.models.AspectRatio
 	15, // 24: stockseer.ai.blueksy.firehose.models.Image.image:type_name -> stockseer.ai.blueksy.firehose.models.Image
@@ -2206,13 +2476,17 @@ var file_internal_models_message_proto_depIdxs = []int32{
 	12, // 33: stockseer.ai.blueksy.firehose.models.Record.external:type_name -> stockseer.ai.blueksy.firehose.models.EmbedExternal
 	19, // 34: stockseer.ai.blueksy.firehose.models.Commit.record:type_name -> stockseer.ai.blueksy.firehose.models.Record
 	20, // 35: stockseer.ai.blueksy.firehose.models.ProtoMessage.commit:type_name -> stockseer.ai.blueksy.firehose.models.Commit
-	22, // 36: stockseer.ai.blueksy.firehose.models.ProtoMessage.account:type_name -> stockseer.ai.blueksy.firehose.models.Account
+	25, // 36: stockseer.ai.blueksy.firehose.models.ProtoMessage.account:type_name -> stockseer.ai.blueksy.firehose.models.Account
 	3,  // 37: stockseer.ai.blueksy.firehose.models.ProtoMessage.identity:type_name -> stockseer.ai.blueksy.firehose.models.Identity
-	38, // [38:38] is the sub-list for method output_type
-	38, // [38:38] is the sub-list for method input_type
-	38, // [38:38] is the sub-list for extension type_name
-	38, // [38:38] is the sub-list for extension extendee
-	0,  // [0:38] is the sub-list for field type_name
+	28, // 38: stockseer.ai.blueksy.firehose.models.ProtoMessage.classifications:type_name -> stockseer.ai.blueksy.firehose.models.ProtoMessage.ClassificationsEntry
+	22, // 39: stockseer.ai.blueksy.firehose.models.ProtoMessage.links:type_name -> stockseer.ai.blueksy.firehose.models.LinkedUrl
+	23, // 40: stockseer.ai.blueksy.firehose.models.ProtoMessage.key_phrases:type_name -> stockseer.ai.blueksy.firehose.models.KeyPhrase
+	24, // 41: stockseer.ai.blueksy.firehose.models.ProtoMessage.ClassificationsEntry.value:type_name -> stockseer.ai.blueksy.firehose.models.Classification
+	42, // [42:42] is the sub-list for method output_type
+	42, // [42:42] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
 }
 
 func init() { file_internal_models_message_proto_init() }
@@ -2240,13 +2514,14 @@ func file_internal_models_message_proto_init() {
 	file_internal_models_message_proto_msgTypes[19].OneofWrappers = []any{}
 	file_internal_models_message_proto_msgTypes[21].OneofWrappers = []any{}
 	file_internal_models_message_proto_msgTypes[22].OneofWrappers = []any{}
+	file_internal_models_message_proto_msgTypes[25].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_models_message_proto_rawDesc), len(file_internal_models_message_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   25,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
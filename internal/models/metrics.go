@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"time"
 
 	"stockseer.ai/blueksy-firehose/internal/logger"
 )
@@ -12,6 +13,12 @@ type CategoryMetrics struct {
 	Positive  int    `json:"positive"`
 	Category  string `json:"category"`
 	Timestamp int64  `json:"timestamp"`
+	// Time is Timestamp as a date, the time field of the metrics time-series.
+	Time time.Time `json:"-" bson:"time"`
+
+	// Confidence weighted counts, only populated when weighting is enabled.
+	WeightedNegative float64 `json:"weighted_negative,omitempty" bson:"weighted_negative,omitempty"`
+	WeightedPositive float64 `json:"weighted_positive,omitempty" bson:"weighted_positive,omitempty"`
 }
 
 // ToJSON marshals the CategoryMetrics struct to a JSON string.
@@ -25,10 +32,69 @@ func (cm *CategoryMetrics) ToJSON() (string, error) {
 
 func (cm *CategoryMetrics) Print(logger logger.Logger) {
 	logger.Info(
-		"Category: %s, Negative: %d, Positive: %d, Timestamp: %d",
+		"Category: %s, Negative: %d (%.2f), Positive: %d (%.2f), Timestamp: %d",
 		cm.Category,
 		cm.Negative,
+		cm.WeightedNegative,
 		cm.Positive,
+		cm.WeightedPositive,
 		cm.Timestamp,
 	)
 }
+
+// TickerMetrics holds sentiment counts for posts mentioning a ticker.
+type TickerMetrics struct {
+	Negative  int    `json:"negative"`
+	Positive  int    `json:"positive"`
+	Ticker    string `json:"ticker"`
+	Timestamp int64  `json:"timestamp"`
+	// Time is Timestamp as a date, the time field of the metrics time-series.
+	Time time.Time `json:"-" bson:"time"`
+
+	// Confidence weighted counts, only populated when weighting is enabled.
+	WeightedNegative float64 `json:"weighted_negative,omitempty" bson:"weighted_negative,omitempty"`
+	WeightedPositive float64 `json:"weighted_positive,omitempty" bson:"weighted_positive,omitempty"`
+}
+
+func (tm *TickerMetrics) Print(logger logger.Logger) {
+	logger.Info(
+		"Ticker: %s, Negative: %d (%.2f), Positive: %d (%.2f), Timestamp: %d",
+		tm.Ticker,
+		tm.Negative,
+		tm.WeightedNegative,
+		tm.Positive,
+		tm.WeightedPositive,
+		tm.Timestamp,
+	)
+}
+
+// RuleMetrics counts the posts a filtering rule evaluated and rejected.
+type RuleMetrics struct {
+	Rule      string `json:"rule"`
+	Evaluated int    `json:"evaluated"`
+	Rejected  int    `json:"rejected"`
+}
+
+// IntervalMetrics is the snapshot of a metrics interval published to the metrics topic.
+type IntervalMetrics struct {
+	Component    string  `json:"component"`
+	Timestamp    int64   `json:"timestamp"`
+	IntervalSecs int     `json:"interval_secs"`
+	PostsPerSec  float64 `json:"posts_per_sec"`
+	TokensPerSec float64 `json:"tokens_per_sec"`
+	TotalPosts   int64   `json:"total_posts"`
+	TotalTokens  int64   `json:"total_tokens"`
+
+	Categories []CategoryMetrics `json:"categories,omitempty"`
+	Tickers    []TickerMetrics   `json:"tickers,omitempty"`
+	Rules      []RuleMetrics     `json:"rules,omitempty"`
+}
+
+// ToJSON marshals the IntervalMetrics struct to a JSON string.
+func (im *IntervalMetrics) ToJSON() (string, error) {
+	jsonData, err := json.Marshal(im)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
@@ -0,0 +1,219 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrWriterClosed is returned when writing to a closed BatchWriter.
+var ErrWriterClosed = errors.New("batch writer is closed")
+
+// batchWriteTimeout bounds a single InsertMany, a slow database slows down the
+// writes instead of failing them until then.
+var batchWriteTimeout = 30 * time.Second
+
+// BatchWriter buffers values and inserts them together with InsertMany, once a batch
+// is full or its oldest value has waited maxAge. The buffer holds up to queueSize
+// values waiting for a batch, Write blocks while it is full, so writers slow down
+// with the database instead of values piling up in memory. The results are reported
+// off the writer's goroutine, at most as many at once as the buffer holds, so slow
+// callbacks hold up the next batch only once that many are running.
+type BatchWriter[T any] struct {
+	repo      Repository[T]
+	batchSize int
+	maxAge    time.Duration
+
+	// Guards closing queue, writers hold it while sending.
+	mu     sync.RWMutex
+	closed bool
+	queue  chan pendingWrite[T]
+	done   chan struct{}
+
+	// Slots of the running done callbacks, and the callbacks Close waits for.
+	callbacks chan struct{}
+	reporting sync.WaitGroup
+
+	// Counters reported by Stats.
+	batches int64
+	written int64
+	failed  int64
+	full    int64
+	waited  int64 // nanoseconds
+	flushNs int64 // nanoseconds spent in InsertMany
+}
+
+// pendingWrite is a value waiting for its batch, done is called with its result.
+type pendingWrite[T any] struct {
+	value *T
+	done  func(error)
+}
+
+// WriteStats are the counters of a BatchWriter.
+type WriteStats struct {
+	Pending  int
+	Capacity int
+	Batches  int64
+	Written  int64
+	Failed   int64
+	// Full counts the writes that waited for room in the buffer, WaitTime their total wait.
+	Full      int64
+	WaitTime  time.Duration
+	FlushTime time.Duration
+}
+
+// NewBatchWriter starts a writer inserting batches of up to batchSize values into repo.
+func NewBatchWriter[T any](
+	repo Repository[T],
+	batchSize int,
+	maxAge time.Duration,
+	queueSize int,
+) *BatchWriter[T] {
+	w := &BatchWriter[T]{
+		repo:      repo,
+		batchSize: max(batchSize, 1),
+		maxAge:    maxAge,
+		queue:     make(chan pendingWrite[T], max(queueSize, 0)),
+		done:      make(chan struct{}),
+		callbacks: make(chan struct{}, max(queueSize, batchSize, 1)),
+	}
+	go w.run()
+	return w
+}
+
+// Write queues value for the next batch and returns, done is called with the result
+// of its insert from a goroutine of its own. Write blocks while the buffer is full and
+// returns ErrWriterClosed, without calling done, once the writer is closed.
+func (w *BatchWriter[T]) Write(value *T, done func(error)) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	pending := pendingWrite[T]{value: value, done: done}
+	select {
+	case w.queue <- pending:
+		return nil
+	default:
+	}
+
+	start := time.Now()
+	w.queue <- pending
+	atomic.AddInt64(&w.full, 1)
+	atomic.AddInt64(&w.waited, int64(time.Since(start)))
+	return nil
+}
+
+// Close stops accepting writes and waits up to timeout for the buffered values to be
+// written and their results reported.
+func (w *BatchWriter[T]) Close(timeout time.Duration) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("timed out flushing the batch writer")
+	}
+}
+
+// Stats returns the writer's counters.
+func (w *BatchWriter[T]) Stats() WriteStats {
+	return WriteStats{
+		Pending:   len(w.queue),
+		Capacity:  cap(w.queue),
+		Batches:   atomic.LoadInt64(&w.batches),
+		Written:   atomic.LoadInt64(&w.written),
+		Failed:    atomic.LoadInt64(&w.failed),
+		Full:      atomic.LoadInt64(&w.full),
+		WaitTime:  time.Duration(atomic.LoadInt64(&w.waited)),
+		FlushTime: time.Duration(atomic.LoadInt64(&w.flushNs)),
+	}
+}
+
+// run collects batches until the queue is closed and drained.
+func (w *BatchWriter[T]) run() {
+	defer func() {
+		w.reporting.Wait()
+		close(w.done)
+	}()
+
+	batch := make([]pendingWrite[T], 0, w.batchSize)
+	timer := time.NewTimer(w.maxAge)
+	timer.Stop()
+
+	for {
+		select {
+		case pending, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(w.maxAge)
+			}
+			batch = append(batch, pending)
+			if len(batch) < w.batchSize {
+				continue
+			}
+		case <-timer.C:
+		}
+
+		timer.Stop()
+		w.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+// flush inserts batch and reports each value's result.
+func (w *BatchWriter[T]) flush(batch []pendingWrite[T]) {
+	if len(batch) == 0 {
+		return
+	}
+
+	values := make([]*T, len(batch))
+	for i, pending := range batch {
+		values[i] = pending.value
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), batchWriteTimeout)
+	start := time.Now()
+	errs := ValueErrors(len(values), w.repo.InsertMany(ctx, values))
+	atomic.AddInt64(&w.flushNs, int64(time.Since(start)))
+	cancel()
+
+	atomic.AddInt64(&w.batches, 1)
+	for i, pending := range batch {
+		if errs[i] != nil {
+			atomic.AddInt64(&w.failed, 1)
+		} else {
+			atomic.AddInt64(&w.written, 1)
+		}
+		if pending.done != nil {
+			w.report(pending.done, errs[i])
+		}
+	}
+}
+
+// report calls done with err from a goroutine of its own, once a callback slot is
+// free.
+func (w *BatchWriter[T]) report(done func(error), err error) {
+	w.callbacks <- struct{}{}
+	w.reporting.Add(1)
+	go func() {
+		defer func() {
+			<-w.callbacks
+			w.reporting.Done()
+		}()
+		done(err)
+	}()
+}
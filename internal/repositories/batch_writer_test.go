@@ -0,0 +1,186 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type record struct {
+	ID int
+}
+
+// batchRepository records the batches it is given, failing the records in fail.
+type batchRepository struct {
+	mu      sync.Mutex
+	batches [][]int
+	fail    map[int]bool
+	block   chan struct{} // When set, InsertMany waits for it to be closed
+}
+
+func (r *batchRepository) InsertMany(ctx context.Context, values []*record) error {
+	if r.block != nil {
+		<-r.block
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []int
+	failed := make(map[int]error)
+	for i, value := range values {
+		ids = append(ids, value.ID)
+		if r.fail[value.ID] {
+			failed[i] = errors.New("duplicate key")
+		}
+	}
+	r.batches = append(r.batches, ids)
+	if len(failed) > 0 {
+		return &BatchError{Failed: failed}
+	}
+	return nil
+}
+
+func (r *batchRepository) Insert(ctx context.Context, value *record) error { return nil }
+func (r *batchRepository) FindByID(ctx context.Context, id string) (*record, error) {
+	return nil, ErrNotFound
+}
+func (r *batchRepository) Find(ctx context.Context, query Query) (*Page[record], error) {
+	return &Page[record]{}, nil
+}
+func (r *batchRepository) Update(ctx context.Context, id string, fields map[string]any) error {
+	return nil
+}
+func (r *batchRepository) Delete(ctx context.Context, id string) error { return nil }
+
+func (r *batchRepository) Batches() [][]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]int(nil), r.batches...)
+}
+
+func TestBatchWriter_BatchesBySizeAndAge(t *testing.T) {
+	repo := &batchRepository{fail: map[int]bool{2: true}}
+	w := NewBatchWriter[record](repo, 3, 50*time.Millisecond, 10)
+
+	results := make(chan error, 4)
+	for id := 1; id <= 4; id++ {
+		if err := w.Write(&record{ID: id}, func(err error) { results <- err }); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// The first three fill a batch, the fourth is written once it is old enough.
+	var failed int
+	for range 4 {
+		select {
+		case err := <-results:
+			if err != nil {
+				failed++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the writes")
+		}
+	}
+	if failed != 1 {
+		t.Errorf("Expected only the failing record to be reported, got %d failures", failed)
+	}
+
+	batches := repo.Batches()
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 1 {
+		t.Errorf("Expected a full batch and a batch of 1, got %v", batches)
+	}
+
+	stats := w.Stats()
+	if stats.Batches != 2 || stats.Written != 3 || stats.Failed != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if err := w.Close(time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestBatchWriter_BackpressureAndFlushOnClose(t *testing.T) {
+	repo := &batchRepository{block: make(chan struct{})}
+	w := NewBatchWriter[record](repo, 1, time.Hour, 1)
+
+	// The first record is being written, the second waits in the buffer and the third
+	// waits for room.
+	written := make(chan struct{})
+	go func() {
+		for id := 1; id <= 3; id++ {
+			_ = w.Write(&record{ID: id}, nil)
+		}
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("Expected the writer to wait while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(repo.block)
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for room in the buffer")
+	}
+
+	if err := w.Close(5 * time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if batches := repo.Batches(); len(batches) != 3 {
+		t.Errorf("Expected every record written before Close returns, got %v", batches)
+	}
+	if err := w.Write(&record{ID: 4}, nil); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed, got %v", err)
+	}
+	if stats := w.Stats(); stats.Full == 0 {
+		t.Error("Expected a write to wait for room")
+	}
+}
+
+func TestValueErrors(t *testing.T) {
+	errs := ValueErrors(3, &BatchError{Failed: map[int]error{1: errors.New("duplicate key")}})
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Errorf("Expected only the second value to fail, got %v", errs)
+	}
+
+	down := errors.New("no reachable servers")
+	for _, err := range ValueErrors(2, down) {
+		if !errors.Is(err, down) {
+			t.Errorf("Expected every value to fail with %v, got %v", down, err)
+		}
+	}
+}
+
+func TestBatchWriter_SlowCallbackDoesNotHoldUpBatches(t *testing.T) {
+	repo := &batchRepository{}
+	w := NewBatchWriter[record](repo, 1, time.Hour, 10)
+
+	release := make(chan struct{})
+	results := make(chan error, 1)
+	if err := w.Write(&record{ID: 1}, func(error) { <-release }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := w.Write(&record{ID: 2}, func(err error) { results <- err }); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case <-results:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the second batch reported while the first callback runs")
+	}
+
+	if err := w.Close(50 * time.Millisecond); err == nil {
+		t.Error("Expected Close to wait for the running callback")
+	}
+	close(release)
+	if err := w.Close(5 * time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
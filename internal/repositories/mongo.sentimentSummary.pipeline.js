@@ -6,11 +6,7 @@
       positive: 1,
       category: 1,
       timestamp: 1,
-      createdAt: {
-        $toDate: {
-          $multiply: ["$timestamp", 1000]
-        }
-      }
+      createdAt: "$time"
     }
   },
   {
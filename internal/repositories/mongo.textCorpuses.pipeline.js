@@ -1,7 +1,7 @@
 [
   {
     $match: {
-      finsentiment: {
+      fin_sentiment: {
         $in: ["positive", "negative"]
       },
       categories: {
@@ -18,9 +18,7 @@
     $project: {
       _id: 0,
       text: "$commit.record.text",
-      createdAtDate: {
-        $toDate: "$commit.record.createdat"
-      }
+      createdAtDate: "$created_at"
     }
   },
   {
@@ -0,0 +1,162 @@
+[
+  {
+    $match: {
+      "links.0": {
+        $exists: true
+      }
+    }
+  },
+  {
+    $project: {
+      _id: 0,
+      links: 1,
+      categories: 1,
+      createdAtDate: "$created_at"
+    }
+  },
+  {
+    $match: {
+      "$expr": {
+        "$gte": [
+          "$createdAtDate",
+          { "$subtract": ["$$NOW", 24 * 60 * 60 * 1000] }
+        ]
+      }
+    }
+  },
+  {
+    $unwind: "$links"
+  },
+  {
+    $unwind: {
+      path: "$categories",
+      preserveNullAndEmptyArrays: true
+    }
+  },
+  {
+    $facet: {
+      last15Minutes: [
+        {
+          $match: {
+            "$expr": {
+              "$gte": [
+                "$createdAtDate",
+                { "$subtract": ["$$NOW", 15 * 60 * 1000] }
+              ]
+            }
+          }
+        },
+        {
+          $group: {
+            _id: {
+              category: "$categories",
+              domain: "$links.domain",
+              kind: "$links.kind"
+            },
+            count: {
+              $sum: 1
+            },
+            articles: {
+              $addToSet: "$links.url"
+            }
+          }
+        },
+        {
+          $sort: {
+            count: -1
+          }
+        },
+        {
+          $limit: 50
+        }
+      ],
+      last1Hour: [
+        {
+          $match: {
+            "$expr": {
+              "$gte": [
+                "$createdAtDate",
+                { "$subtract": ["$$NOW", 60 * 60 * 1000] }
+              ]
+            }
+          }
+        },
+        {
+          $group: {
+            _id: {
+              category: "$categories",
+              domain: "$links.domain",
+              kind: "$links.kind"
+            },
+            count: {
+              $sum: 1
+            },
+            articles: {
+              $addToSet: "$links.url"
+            }
+          }
+        },
+        {
+          $sort: {
+            count: -1
+          }
+        },
+        {
+          $limit: 50
+        }
+      ],
+      last1Day: [
+        {
+          $group: {
+            _id: {
+              category: "$categories",
+              domain: "$links.domain",
+              kind: "$links.kind"
+            },
+            count: {
+              $sum: 1
+            },
+            articles: {
+              $addToSet: "$links.url"
+            }
+          }
+        },
+        {
+          $sort: {
+            count: -1
+          }
+        },
+        {
+          $limit: 50
+        }
+      ],
+      topArticlesLast1Day: [
+        {
+          $group: {
+            _id: {
+              category: "$categories",
+              url: "$links.url"
+            },
+            domain: {
+              $first: "$links.domain"
+            },
+            title: {
+              $first: "$links.title"
+            },
+            count: {
+              $sum: 1
+            }
+          }
+        },
+        {
+          $sort: {
+            count: -1
+          }
+        },
+        {
+          $limit: 50
+        }
+      ]
+    }
+  }
+]
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// classificationDocument is the stored form of a cached classification.
+type classificationDocument struct {
+	Key          string    `bson:"_id"`
+	Classifier   string    `bson:"classifier"`
+	Labels       []string  `bson:"labels"`
+	Scores       []float64 `bson:"scores"`
+	ModelVersion string    `bson:"model_version"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+}
+
+// MongoClassificationStore implements ClassificationStore using MongoDB.
+type MongoClassificationStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoClassificationStore creates the store and makes sure expired entries are
+// removed by Mongo's TTL monitor.
+func NewMongoClassificationStore(
+	client *mongo.Client,
+	dbName, collectionName string,
+) (*MongoClassificationStore, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+
+	_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoClassificationStore{collection: collection}, nil
+}
+
+// Get returns the cached classification for key if it has not expired.
+func (s *MongoClassificationStore) Get(key string) (*models.Classification, bool, error) {
+	filter := bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}
+
+	var doc classificationDocument
+	err := s.collection.FindOne(context.Background(), filter).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &models.Classification{
+		Classifier:   doc.Classifier,
+		Labels:       doc.Labels,
+		Scores:       doc.Scores,
+		ModelVersion: doc.ModelVersion,
+	}, true, nil
+}
+
+// Set stores value under key for ttl.
+func (s *MongoClassificationStore) Set(key string, value *models.Classification, ttl time.Duration) error {
+	doc := classificationDocument{
+		Key:          key,
+		Classifier:   value.Classifier,
+		Labels:       value.Labels,
+		Scores:       value.Scores,
+		ModelVersion: value.ModelVersion,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+
+	_, err := s.collection.ReplaceOne(
+		context.Background(),
+		bson.M{"_id": key},
+		doc,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// Codec converts values to the documents stored for them and back. A new stored
+// type only needs a codec when its BSON encoding is not the document to store.
+type Codec[T any] interface {
+	Encode(value *T) (any, error)
+	Decode(doc bson.Raw) (*T, error)
+	// ID returns the id value is stored under, empty to let Mongo assign one.
+	ID(value *T) string
+}
+
+// upsertCodec is implemented by codecs whose stored documents keep fields when they
+// are stored again. Upsert returns the update storing doc, as returned by Encode,
+// over the document stored under the same id.
+type upsertCodec interface {
+	Upsert(doc any) bson.M
+}
+
+// bsonCodec stores values as their BSON encoding.
+type bsonCodec[T any] struct{}
+
+func (bsonCodec[T]) Encode(value *T) (any, error) {
+	return value, nil
+}
+
+func (bsonCodec[T]) Decode(doc bson.Raw) (*T, error) {
+	var value T
+	if err := bson.Unmarshal(doc, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func (bsonCodec[T]) ID(value *T) string {
+	return ""
+}
+
+// messageCodec stores posts in their JSON form, the field names the aggregation
+// pipelines query, with the dates they were created, received and stored, which
+// stored posts expire from. Posts are stored under their DocumentID, with their
+// AT URI in the uri field. A post stored again keeps the date it was first stored,
+// so redeliveries do not postpone its expiry.
+type messageCodec struct{}
+
+// storedOnlyFields are added to the stored posts and are not fields of ProtoMessage.
+var storedOnlyFields = []string{"_id", "uri", "datetime", "created_at", "stored_at"}
+
+func (messageCodec) Encode(value *models.ProtoMessage) (any, error) {
+	data, err := value.WithDateTime()
+	if err != nil {
+		return nil, err
+	}
+	doc := *data
+	doc["stored_at"] = time.Now().UTC()
+	if id := value.DocumentID(); id != "" {
+		doc["_id"] = id
+	}
+	if uri := value.URI(); uri != "" {
+		doc["uri"] = uri
+	}
+	return doc, nil
+}
+
+func (messageCodec) Upsert(doc any) bson.M {
+	fields := doc.(map[string]interface{})
+	set := make(bson.M, len(fields))
+	for field, value := range fields {
+		if field != "_id" && field != "stored_at" {
+			set[field] = value
+		}
+	}
+	return bson.M{"$set": set, "$setOnInsert": bson.M{"stored_at": fields["stored_at"]}}
+}
+
+func (messageCodec) ID(value *models.ProtoMessage) string {
+	return value.DocumentID()
+}
+
+func (messageCodec) Decode(doc bson.Raw) (*models.ProtoMessage, error) {
+	// Relaxed extended JSON is the JSON the document was stored from.
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert document to JSON: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to convert document to map: %w", err)
+	}
+	for _, field := range storedOnlyFields {
+		delete(fields, field)
+	}
+
+	msg := &models.ProtoMessage{}
+	if err := msg.FromJSONMap(fields); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// stored returns the document Mongo would return for value, with an id added unless
+// the codec gave it one.
+func stored[T any](t *testing.T, codec Codec[T], value *T) bson.Raw {
+	t.Helper()
+
+	doc, err := codec.Encode(value)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var fields bson.D
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if codec.ID(value) == "" {
+		fields = append(bson.D{{Key: "_id", Value: primitive.NewObjectID()}}, fields...)
+	}
+	data, err = bson.Marshal(fields)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return data
+}
+
+func TestMessageCodec_RoundTrip(t *testing.T) {
+	msg := &models.ProtoMessage{
+		Did:    "did:plc:abc",
+		TimeUs: 1760000000123456,
+		Kind:   "commit",
+		Commit: &models.Commit{
+			Collection: "app.bsky.feed.post",
+			Rkey:       "3kxyz",
+			Record: &models.Record{
+				Text:  "$SPY is falling",
+				Langs: []string{"en"},
+			},
+		},
+		Classifications: map[string]*models.Classification{
+			"category": {Classifier: "category", Labels: []string{"economy"}, Scores: []float64{0.92}},
+		},
+	}
+
+	codec := Codec[models.ProtoMessage](messageCodec{})
+	doc := stored(t, codec, msg)
+	for _, field := range []string{"datetime", "created_at", "stored_at"} {
+		if value, err := doc.LookupErr(field); err != nil || value.Type != bson.TypeDateTime {
+			t.Errorf("Expected the %s field to be stored as a date, got %v (%v)", field, value, err)
+		}
+	}
+	if received := doc.Lookup("datetime").Time(); !received.Equal(time.UnixMilli(1760000000123)) {
+		t.Errorf("Expected the post to be received at %s, got %s", time.UnixMilli(1760000000123), received)
+	}
+	const uri = "at://did:plc:abc/app.bsky.feed.post/3kxyz"
+	if id := doc.Lookup("_id").StringValue(); id != uri {
+		t.Errorf("Expected the post to be stored under %s, got %s", uri, id)
+	}
+	if stored := doc.Lookup("uri").StringValue(); stored != uri {
+		t.Errorf("Expected the uri field %s, got %s", uri, stored)
+	}
+	if text := doc.Lookup("commit", "record", "text").StringValue(); text != "$SPY is falling" {
+		t.Errorf("Expected the post text under commit.record.text, got %q", text)
+	}
+
+	decoded, err := codec.Decode(doc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded.Did != msg.Did || decoded.TimeUs != msg.TimeUs || decoded.Commit.Rkey != msg.Commit.Rkey ||
+		decoded.Commit.Record.Text != msg.Commit.Record.Text || len(decoded.Commit.Record.Langs) != 1 ||
+		decoded.Classifications["category"].GetScores()[0] != 0.92 {
+		t.Errorf("Expected %v, got %v", msg, decoded)
+	}
+}
+
+func TestMessageCodec_ID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		msg      *models.ProtoMessage
+		expected string
+	}{
+		{
+			name: "AT URI",
+			msg: &models.ProtoMessage{
+				Did:    "did:plc:abc",
+				Commit: &models.Commit{Collection: "app.bsky.feed.post", Rkey: "3kxyz", Cid: "bafyrei"},
+			},
+			expected: "at://did:plc:abc/app.bsky.feed.post/3kxyz",
+		},
+		{
+			name:     "CID without rkey",
+			msg:      &models.ProtoMessage{Did: "did:plc:abc", Commit: &models.Commit{Cid: "bafyrei"}},
+			expected: "bafyrei",
+		},
+		{name: "Identity event", msg: &models.ProtoMessage{Did: "did:plc:abc", Kind: "identity"}},
+	}
+
+	codec := messageCodec{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if id := codec.ID(tc.msg); id != tc.expected {
+				t.Errorf("Expected id %q, got %q", tc.expected, id)
+			}
+
+			doc := stored(t, Codec[models.ProtoMessage](codec), tc.msg)
+			if _, err := doc.LookupErr("uri"); (err == nil) != (tc.msg.URI() != "") {
+				t.Errorf("Expected the uri field only for posts with a URI, got %v", doc)
+			}
+		})
+	}
+}
+
+func TestBSONCodec_RoundTrip(t *testing.T) {
+	metrics := &models.CategoryMetrics{Category: "economy", Negative: 4, Positive: 2, Timestamp: 1760000000}
+
+	codec := Codec[models.CategoryMetrics](bsonCodec[models.CategoryMetrics]{})
+	decoded, err := codec.Decode(stored(t, codec, metrics))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *decoded != *metrics {
+		t.Errorf("Expected %v, got %v", metrics, decoded)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	oid := primitive.NewObjectID()
+	for _, id := range []any{oid, "at://did:plc:abc/app.bsky.feed.post/3kxyz"} {
+		typ, data, err := bson.MarshalValue(id)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		value := bson.RawValue{Type: typ, Value: data}
+
+		cursor, err := encodeCursor(value)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !after.Equal(value) {
+			t.Errorf("Expected cursor of %v to continue after it, got %v", id, after)
+		}
+	}
+
+	if _, err := decodeCursor("not a cursor"); err == nil {
+		t.Error("Expected an error for an invalid cursor")
+	}
+}
+
+func TestAfterID(t *testing.T) {
+	typ, data, err := bson.MarshalValue("at://did:plc:abc/app.bsky.feed.post/3kxyz")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clauses, ok := afterID(bson.RawValue{Type: typ, Value: data})["$or"].(bson.A)
+	if !ok || len(clauses) != 2 {
+		t.Fatalf("Expected ids after a URI to be matched by value or type, got %v", clauses)
+	}
+	later := clauses[1].(bson.M)["_id"].(bson.M)["$type"].(bson.A)
+	if !slices.Contains(later, any("objectId")) || slices.Contains(later, any("string")) {
+		t.Errorf("Expected ObjectIDs and no other strings to follow a URI, got %v", later)
+	}
+
+	typ, data, err = bson.MarshalValue(primitive.NewObjectID())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	clauses = afterID(bson.RawValue{Type: typ, Value: data})["$or"].(bson.A)
+	later = clauses[1].(bson.M)["_id"].(bson.M)["$type"].(bson.A)
+	if slices.Contains(later, any("string")) || slices.Contains(later, any("objectId")) {
+		t.Errorf("Expected URIs not to follow an ObjectID, got %v", later)
+	}
+}
+
+func TestIDFilter(t *testing.T) {
+	oid := primitive.NewObjectID()
+	filter := idFilter(oid.Hex())
+	ids, ok := filter["_id"].(bson.M)["$in"].(bson.A)
+	if !ok || len(ids) != 2 || ids[1] != oid {
+		t.Errorf("Expected the hex id to match the ObjectID too, got %v", filter)
+	}
+
+	if filter := idFilter("at://did:plc:abc/app.bsky.feed.post/3kxyz"); filter["_id"] != "at://did:plc:abc/app.bsky.feed.post/3kxyz" {
+		t.Errorf("Expected a string id to match as is, got %v", filter)
+	}
+}
+
+func TestMessageCodec_UpsertKeepsStoredAt(t *testing.T) {
+	msg := &models.ProtoMessage{
+		Did:    "did:plc:abc",
+		TimeUs: 1760000000123456,
+		Kind:   "commit",
+		Commit: &models.Commit{Collection: "app.bsky.feed.post", Rkey: "3kxyz", Record: &models.Record{Text: "hi"}},
+	}
+
+	codec := messageCodec{}
+	doc, err := codec.Encode(msg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	update := codec.Upsert(doc)
+
+	set := update["$set"].(bson.M)
+	for _, field := range []string{"_id", "stored_at"} {
+		if _, ok := set[field]; ok {
+			t.Errorf("Expected %s not to be set on every store", field)
+		}
+	}
+	if _, ok := set["created_at"]; !ok {
+		t.Error("Expected the post's fields to be set")
+	}
+	if storedAt, ok := update["$setOnInsert"].(bson.M)["stored_at"].(time.Time); !ok || storedAt.IsZero() {
+		t.Errorf("Expected stored_at to be set on insert, got %v", update["$setOnInsert"])
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// replayedRetention is how long a replayed dead letter is kept, so its attempts and
+// replays still add up when its message fails again in that time.
+const replayedRetention = 7 * 24 * time.Hour
+
+// MongoDeadLetterStore implements DeadLetterStore using MongoDB.
+type MongoDeadLetterStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDeadLetterStore creates the store and makes sure replayed dead letters are
+// removed by Mongo's TTL monitor once replayedRetention has passed.
+func NewMongoDeadLetterStore(
+	client *mongo.Client,
+	dbName, collectionName string,
+) (*MongoDeadLetterStore, error) {
+	collection := client.Database(dbName).Collection(collectionName)
+
+	_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "replayed_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(replayedRetention.Seconds())),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoDeadLetterStore{collection: collection}, nil
+}
+
+// Add stores letter. A payload failing again replaces the stage, reason and time of
+// its earlier entry, adds to its attempts and is listed again if it was replayed.
+func (s *MongoDeadLetterStore) Add(letter *models.DeadLetter) error {
+	_, err := s.collection.UpdateOne(
+		context.Background(),
+		bson.M{"_id": letter.ID},
+		bson.M{
+			"$set": bson.M{
+				"stage":     letter.Stage,
+				"reason":    letter.Reason,
+				"topic":     letter.Topic,
+				"payload":   letter.Payload,
+				"failed_at": letter.FailedAt,
+			},
+			"$inc":         bson.M{"attempts": letter.Attempts},
+			"$setOnInsert": bson.M{"replays": letter.Replays},
+			"$unset":       bson.M{"replayed_at": ""},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// List returns up to limit dead letters waiting to be replayed, oldest first, leaving
+// out those replayed maxReplays times already. A limit or maxReplays of 0 does not
+// restrict them.
+func (s *MongoDeadLetterStore) List(limit, maxReplays int) ([]*models.DeadLetter, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "failed_at", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	filter := bson.M{"replayed_at": bson.M{"$exists": false}}
+	if maxReplays > 0 {
+		filter["replays"] = bson.M{"$lt": maxReplays}
+	}
+	cur, err := s.collection.Find(context.Background(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var letters []*models.DeadLetter
+	if err := cur.All(context.Background(), &letters); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// MarkReplayed records that the dead letter with id was replayed, counting the
+// replay, or undoes it when replayed is false.
+func (s *MongoDeadLetterStore) MarkReplayed(id string, replayed bool) error {
+	update := bson.M{"$set": bson.M{"replayed_at": time.Now().UTC()}, "$inc": bson.M{"replays": 1}}
+	if !replayed {
+		update = bson.M{"$unset": bson.M{"replayed_at": ""}, "$inc": bson.M{"replays": -1}}
+	}
+
+	_, err := s.collection.UpdateOne(context.Background(), bson.M{"_id": id}, update)
+	return err
+}
+
+// Delete removes the dead letter with id.
+func (s *MongoDeadLetterStore) Delete(id string) error {
+	_, err := s.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	return err
+}
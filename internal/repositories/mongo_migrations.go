@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// legacyDateTimeLayout is the time.Time.String format datetime was stored in.
+const legacyDateTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// MigrateMessageDates stores the datetime and created_at fields of the posts stored
+// before they were dates, and the stored_at field the posts expire from, as the time
+// they were received, batchSize posts at a time, at least one. It returns the number
+// of posts updated. Posts are updated in place, so it can be run again after an
+// interruption.
+func MigrateMessageDates(ctx context.Context, collection *mongo.Collection, batchSize int) (int64, error) {
+	batchSize = max(batchSize, 1)
+	filter := bson.M{"$or": bson.A{
+		bson.M{"datetime": bson.M{"$not": bson.M{"$type": "date"}}},
+		bson.M{"created_at": bson.M{"$exists": false}},
+		bson.M{"stored_at": bson.M{"$exists": false}},
+	}}
+	opts := options.Find().
+		SetBatchSize(int32(batchSize)).
+		SetProjection(bson.M{
+			"time_us":                  1,
+			"datetime":                 1,
+			"stored_at":                1,
+			"commit.record.created_at": 1,
+			"commit.record.createdat":  1,
+		})
+
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var updated int64
+	writes := make([]mongo.WriteModel, 0, batchSize)
+	flush := func() error {
+		if len(writes) == 0 {
+			return nil
+		}
+		result, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if result != nil {
+			updated += result.ModifiedCount
+		}
+		writes = writes[:0]
+		return err
+	}
+
+	for cur.Next(ctx) {
+		receivedAt, createdAt := messageDates(cur.Current)
+		set := bson.M{"datetime": receivedAt, "created_at": createdAt}
+		if _, err := cur.Current.LookupErr("stored_at"); err != nil {
+			set["stored_at"] = receivedAt
+		}
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": cur.Current.Lookup("_id")}).
+			SetUpdate(bson.M{"$set": set}))
+		if len(writes) >= batchSize {
+			if err := flush(); err != nil {
+				return updated, err
+			}
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return updated, err
+	}
+	return updated, flush()
+}
+
+// MigrateMetricsTime stores the time field of the metrics stored before they had one,
+// from their Unix timestamp, and returns the number of metrics updated.
+func MigrateMetricsTime(ctx context.Context, collection *mongo.Collection) (int64, error) {
+	result, err := collection.UpdateMany(
+		ctx,
+		bson.M{"time": bson.M{"$exists": false}, "timestamp": bson.M{"$type": "number"}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"time": bson.M{"$toDate": bson.M{"$multiply": bson.A{"$timestamp", 1000}}},
+		}}}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// messageDates returns the dates a stored post was received and created, as
+// ProtoMessage computes them. The receive time is read from time_us, which is stored
+// as a string, or else from datetime.
+func messageDates(doc bson.Raw) (receivedAt, createdAt time.Time) {
+	switch timeUs := doc.Lookup("time_us"); timeUs.Type {
+	case bson.TypeString:
+		if us, err := strconv.ParseInt(timeUs.StringValue(), 10, 64); err == nil {
+			receivedAt = time.UnixMicro(us).UTC()
+		}
+	case bson.TypeInt64, bson.TypeInt32, bson.TypeDouble:
+		if us, ok := timeUs.AsInt64OK(); ok {
+			receivedAt = time.UnixMicro(us).UTC()
+		}
+	}
+	if receivedAt.IsZero() {
+		datetime := doc.Lookup("datetime")
+		if value, ok := datetime.StringValueOK(); ok {
+			if parsed, err := time.Parse(legacyDateTimeLayout, value); err == nil {
+				receivedAt = parsed.UTC()
+			}
+		} else if value, ok := datetime.TimeOK(); ok {
+			receivedAt = value.UTC()
+		}
+	}
+
+	// Posts stored by early versions have lower case field names.
+	value, ok := doc.Lookup("commit", "record", "created_at").StringValueOK()
+	if !ok {
+		value, _ = doc.Lookup("commit", "record", "createdat").StringValueOK()
+	}
+
+	// Without a receive time createdAt is checked against the current time, and
+	// also stands for the receive time.
+	if receivedAt.IsZero() {
+		createdAt = models.CreatedAt(value, time.Now().UTC())
+		return createdAt, createdAt
+	}
+	return receivedAt, models.CreatedAt(value, receivedAt)
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMessageDates(t *testing.T) {
+	received := time.Date(2025, 10, 9, 12, 0, 0, 0, time.UTC)
+	timeUs := "1760011200000000" // received
+
+	tests := []struct {
+		name         string
+		doc          bson.M
+		wantReceived time.Time
+		wantCreated  time.Time
+	}{
+		{
+			name: "Created before received",
+			doc: bson.M{"time_us": timeUs, "commit": bson.M{"record": bson.M{
+				"created_at": "2025-10-09T11:59:58.123Z",
+			}}},
+			wantReceived: received,
+			wantCreated:  time.Date(2025, 10, 9, 11, 59, 58, 123000000, time.UTC),
+		},
+		{
+			name: "Offset and lower case field name",
+			doc: bson.M{"time_us": timeUs, "commit": bson.M{"record": bson.M{
+				"createdat": "2025-10-09T13:59:00+02:00",
+			}}},
+			wantReceived: received,
+			wantCreated:  time.Date(2025, 10, 9, 11, 59, 0, 0, time.UTC),
+		},
+		{
+			name: "Without time zone",
+			doc: bson.M{"time_us": timeUs, "commit": bson.M{"record": bson.M{
+				"created_at": "2025-10-09T11:59:00.5",
+			}}},
+			wantReceived: received,
+			wantCreated:  time.Date(2025, 10, 9, 11, 59, 0, 500000000, time.UTC),
+		},
+		{
+			name: "Within the allowed skew",
+			doc: bson.M{"time_us": timeUs, "commit": bson.M{"record": bson.M{
+				"created_at": "2025-10-09T12:04:00Z",
+			}}},
+			wantReceived: received,
+			wantCreated:  time.Date(2025, 10, 9, 12, 4, 0, 0, time.UTC),
+		},
+		{
+			name: "In the future",
+			doc: bson.M{"time_us": timeUs, "commit": bson.M{"record": bson.M{
+				"created_at": "2031-01-01T00:00:00Z",
+			}}},
+			wantReceived: received,
+			wantCreated:  received,
+		},
+		{
+			name: "Placeholder",
+			doc: bson.M{"time_us": timeUs, "commit": bson.M{"record": bson.M{
+				"created_at": "1970-01-01T00:00:00Z",
+			}}},
+			wantReceived: received,
+			wantCreated:  received,
+		},
+		{
+			name: "Malformed",
+			doc: bson.M{"time_us": timeUs, "commit": bson.M{"record": bson.M{
+				"created_at": "yesterday",
+			}}},
+			wantReceived: received,
+			wantCreated:  received,
+		},
+		{
+			name:         "Legacy datetime string",
+			doc:          bson.M{"datetime": received.String()},
+			wantReceived: received,
+			wantCreated:  received,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(tt.doc)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			gotReceived, gotCreated := messageDates(data)
+			if !gotReceived.Equal(tt.wantReceived) {
+				t.Errorf("Expected received at %s, got %s", tt.wantReceived, gotReceived)
+			}
+			if !gotCreated.Equal(tt.wantCreated) {
+				t.Errorf("Expected created at %s, got %s", tt.wantCreated, gotCreated)
+			}
+		})
+	}
+}
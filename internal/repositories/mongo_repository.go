@@ -2,69 +2,289 @@ package repositories
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"stockseer.ai/blueksy-firehose/internal/models"
 )
 
 // MongoRepository implements the Repository interface using MongoDB.
-type MongoRepository struct {
+type MongoRepository[T any] struct {
 	collection *mongo.Collection
+	codec      Codec[T]
 }
 
-// NewMongoRepository creates a new instance of MongoRepository.
-func NewMongoRepository(client *mongo.Client, dbName, collectionName string) *MongoRepository {
+// Ensure MongoRepository implements the Repository interface.
+var _ Repository[models.CategoryMetrics] = (*MongoRepository[models.CategoryMetrics])(nil)
+
+// NewMongoRepository creates a repository storing values of T as their BSON encoding.
+func NewMongoRepository[T any](client *mongo.Client, dbName, collectionName string) *MongoRepository[T] {
+	collection := client.Database(dbName).Collection(collectionName)
+	return newMongoRepository(collection, Codec[T](bsonCodec[T]{}))
+}
+
+// NewMongoMessageRepository creates a repository storing posts in their JSON form,
+// under their AT URI. The URI being the id, no two stored posts have the same URI.
+func NewMongoMessageRepository(
+	client *mongo.Client,
+	dbName, collectionName string,
+) *MongoRepository[models.ProtoMessage] {
 	collection := client.Database(dbName).Collection(collectionName)
-	return &MongoRepository{collection: collection}
+	return newMongoRepository(collection, Codec[models.ProtoMessage](messageCodec{}))
 }
 
-// Insert inserts a document into the MongoDB collection.
-func (r *MongoRepository) Insert(data interface{}) error {
-	switch v := data.(type) {
-	case *models.ProtoMessage:
-		// handle ProtoMessage
-		_data, _ := v.WithDateTime()
-		_, err := r.collection.InsertOne(context.Background(), _data)
+func newMongoRepository[T any](collection *mongo.Collection, codec Codec[T]) *MongoRepository[T] {
+	return &MongoRepository[T]{collection: collection, codec: codec}
+}
+
+// Insert inserts a document into the MongoDB collection. A value with an id replaces
+// the document stored under it, or updates it when its codec keeps fields, so storing
+// it again has no effect.
+func (r *MongoRepository[T]) Insert(ctx context.Context, value *T) error {
+	doc, err := r.codec.Encode(value)
+	if err != nil {
 		return err
-	case models.CategoryMetrics:
-		// handle CategoryMetrics
-		_, err := r.collection.InsertOne(context.Background(), data)
+	}
+
+	if id := r.codec.ID(value); id != "" {
+		if codec, ok := r.codec.(upsertCodec); ok {
+			_, err = r.collection.UpdateOne(ctx, bson.M{"_id": id}, codec.Upsert(doc), options.Update().SetUpsert(true))
+			return err
+		}
+		_, err = r.collection.ReplaceOne(ctx, bson.M{"_id": id}, doc, options.Replace().SetUpsert(true))
 		return err
-	default:
-		return fmt.Errorf("unsupported data type: %T", data)
 	}
+	_, err = r.collection.InsertOne(ctx, doc)
+	return err
+}
+
+// upsertModel is the write storing doc under id, over the document stored under it.
+func (r *MongoRepository[T]) upsertModel(id string, doc any) mongo.WriteModel {
+	if codec, ok := r.codec.(upsertCodec); ok {
+		return mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(codec.Upsert(doc)).SetUpsert(true)
+	}
+	return mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": id}).SetReplacement(doc).SetUpsert(true)
 }
 
-// FindAll retrieves all documents from the collection.
-func (r *MongoRepository) FindAll() ([]interface{}, error) {
-	cur, err := r.collection.Find(context.Background(), bson.D{})
+// InsertMany inserts values in a single unordered bulk write, so a failing document
+// does not stop the others. Values with an id replace their stored document, like
+// Insert. Documents that fail are reported in a *BatchError.
+func (r *MongoRepository[T]) InsertMany(ctx context.Context, values []*T) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(values))
+	indexes := make([]int, 0, len(values)) // Index in values of each write
+	failed := make(map[int]error)
+	for i, value := range values {
+		doc, err := r.codec.Encode(value)
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+
+		if id := r.codec.ID(value); id != "" {
+			writes = append(writes, r.upsertModel(id, doc))
+		} else {
+			writes = append(writes, mongo.NewInsertOneModel().SetDocument(doc))
+		}
+		indexes = append(indexes, i)
+	}
+
+	if len(writes) > 0 {
+		_, err := r.collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		var bulkErr mongo.BulkWriteException
+		switch {
+		case errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil:
+			for _, writeErr := range bulkErr.WriteErrors {
+				failed[indexes[writeErr.Index]] = writeErr
+			}
+		case err != nil:
+			return err
+		}
+	}
+
+	if len(failed) > 0 {
+		return &BatchError{Failed: failed}
+	}
+	return nil
+}
+
+// FindByID retrieves a document by its ID, ErrNotFound when there is none.
+func (r *MongoRepository[T]) FindByID(ctx context.Context, id string) (*T, error) {
+	raw, err := r.collection.FindOne(ctx, idFilter(id)).Raw()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.codec.Decode(raw)
+}
+
+// Find retrieves a page of the documents matching query, ordered by id. Only the page
+// is loaded in memory. Ids of different types are ordered by type as Mongo sorts
+// them, so posts stored with a URI id come before those stored with an ObjectID.
+func (r *MongoRepository[T]) Find(ctx context.Context, query Query) (*Page[T], error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	filter := bson.M{}
+	for field, condition := range query.Filter {
+		filter[field] = condition
+	}
+	if query.Cursor != "" {
+		after, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.M{"$and": bson.A{filter, afterID(after)}}
+	}
+
+	// One more document than the page tells whether there is a next page.
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit) + 1)
+	if len(query.Projection) > 0 {
+		projection := bson.D{}
+		for _, field := range query.Projection {
+			projection = append(projection, bson.E{Key: field, Value: 1})
+		}
+		opts.SetProjection(projection)
+	}
+
+	cur, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
-	var results []interface{}
-	if err = cur.All(context.Background(), &results); err != nil {
+	defer cur.Close(ctx)
+
+	page := &Page[T]{}
+	var last bson.RawValue
+	for len(page.Items) < limit && cur.Next(ctx) {
+		item, err := r.codec.Decode(cur.Current)
+		if err != nil {
+			return nil, err
+		}
+		page.Items = append(page.Items, item)
+		last = cur.Current.Lookup("_id")
+	}
+	if err := cur.Err(); err != nil {
 		return nil, err
 	}
-	return results, nil
-}
 
-// FindByID retrieves a document by its ID.
-func (r *MongoRepository) FindByID(id string) (interface{}, error) {
-	var result interface{}
-	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&result)
-	return result, err
+	if len(page.Items) == limit && cur.Next(ctx) {
+		if page.Next, err = encodeCursor(last); err != nil {
+			return nil, err
+		}
+	}
+	return page, cur.Err()
 }
 
-// Update updates a document in the MongoDB collection.
-func (r *MongoRepository) Update(id string, data interface{}) error {
-	_, err := r.collection.UpdateOne(context.Background(), bson.M{"_id": id}, bson.M{"$set": data})
+// Update sets fields of the document with id.
+func (r *MongoRepository[T]) Update(ctx context.Context, id string, fields map[string]any) error {
+	_, err := r.collection.UpdateOne(ctx, idFilter(id), bson.M{"$set": fields})
 	return err
 }
 
-// DeleteByID deletes a document by its ID.
-func (r *MongoRepository) Delete(id string) error {
-	_, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+// Delete deletes a document by its ID.
+func (r *MongoRepository[T]) Delete(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, idFilter(id))
 	return err
 }
+
+// idFilter matches the document with id. Documents inserted without an id get an
+// ObjectID, which is matched by its hex form.
+func idFilter(id string) bson.M {
+	if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+		return bson.M{"_id": bson.M{"$in": bson.A{id, oid}}}
+	}
+	return bson.M{"_id": id}
+}
+
+// idTypeOrder groups the $type aliases of ids in the order Mongo sorts their types.
+var idTypeOrder = [][]string{
+	{"null"},
+	{"double", "int", "long", "decimal"},
+	{"symbol", "string"},
+	{"object"},
+	{"array"},
+	{"binData"},
+	{"objectId"},
+	{"bool"},
+	{"date"},
+	{"timestamp"},
+	{"regex"},
+}
+
+// idTypeGroups maps the type of an id to its group in idTypeOrder.
+var idTypeGroups = map[bsontype.Type]int{
+	bsontype.Null:             0,
+	bsontype.Double:           1,
+	bsontype.Int32:            1,
+	bsontype.Int64:            1,
+	bsontype.Decimal128:       1,
+	bsontype.Symbol:           2,
+	bsontype.String:           2,
+	bsontype.EmbeddedDocument: 3,
+	bsontype.Array:            4,
+	bsontype.Binary:           5,
+	bsontype.ObjectID:         6,
+	bsontype.Boolean:          7,
+	bsontype.DateTime:         8,
+	bsontype.Timestamp:        9,
+	bsontype.Regex:            10,
+}
+
+// afterID matches the documents sorted after the one with id. A query's $gt only
+// compares ids of the same type, so ids of the types sorted later are matched by
+// type, otherwise paging from a URI id would skip every ObjectID.
+func afterID(id bson.RawValue) bson.M {
+	group, ok := idTypeGroups[id.Type]
+	if !ok || group == len(idTypeOrder)-1 {
+		return bson.M{"_id": bson.M{"$gt": id}}
+	}
+
+	var later bson.A
+	for _, aliases := range idTypeOrder[group+1:] {
+		for _, alias := range aliases {
+			later = append(later, alias)
+		}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{"_id": bson.M{"$gt": id}},
+		bson.M{"_id": bson.M{"$type": later}},
+	}}
+}
+
+// encodeCursor returns the cursor of the page following the document with id.
+// Cursors hold the id's BSON encoding, so they work whatever the type of the ids.
+func encodeCursor(id bson.RawValue) (string, error) {
+	data, err := bson.Marshal(bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor returns the id a cursor continues after.
+func decodeCursor(cursor string) (bson.RawValue, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	id, err := bson.Raw(data).LookupErr("_id")
+	if err != nil {
+		return bson.RawValue{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Server error codes of an index that exists with other options or keys, and of a
+// collection that already exists.
+const (
+	indexOptionsConflict  = 85
+	indexKeySpecsConflict = 86
+	namespaceExists       = 48
+)
+
+// timeSeriesIndexesVersion is the first major server version indexing the measurement
+// fields of time-series collections, such as category and ticker.
+const timeSeriesIndexesVersion = 6
+
+// MongoSchema names the collections posts and metrics are stored in and how they are
+// kept.
+type MongoSchema struct {
+	Messages string
+	Metrics  string
+	// MessageTTL removes stored posts this long after they were stored, 0 keeps them.
+	MessageTTL time.Duration
+	// MetricsTimeSeries creates the metrics collection as a time-series collection.
+	// A collection that already exists is left as it is.
+	MetricsTimeSeries bool
+}
+
+// EnsureMongoSchema creates the collections and indexes of schema in db. Indexes that
+// exist with other options, e.g. after MessageTTL changed, are replaced, so it is run
+// on every startup. A time-series metrics collection is only indexed from MongoDB 6.0
+// on, earlier servers cannot index its category and ticker fields.
+func EnsureMongoSchema(ctx context.Context, db *mongo.Database, schema MongoSchema) error {
+	if schema.MetricsTimeSeries {
+		if err := ensureTimeSeries(ctx, db, schema.Metrics, "time"); err != nil {
+			return fmt.Errorf("failed to create the %s collection: %w", schema.Metrics, err)
+		}
+	}
+
+	if err := ensureIndexes(ctx, db.Collection(schema.Messages), messageIndexes(schema.MessageTTL)); err != nil {
+		return fmt.Errorf("failed to index the %s collection: %w", schema.Messages, err)
+	}
+
+	indexable, err := metricsIndexable(ctx, db, schema.Metrics)
+	if err != nil {
+		return fmt.Errorf("failed to inspect the %s collection: %w", schema.Metrics, err)
+	}
+	if !indexable {
+		return nil
+	}
+	if err := ensureIndexes(ctx, db.Collection(schema.Metrics), metricsIndexes()); err != nil {
+		return fmt.Errorf("failed to index the %s collection: %w", schema.Metrics, err)
+	}
+	return nil
+}
+
+// metricsIndexable reports whether the metrics collection name can have the metrics
+// indexes, which a time-series collection only can from timeSeriesIndexesVersion on.
+func metricsIndexable(ctx context.Context, db *mongo.Database, name string) (bool, error) {
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": name})
+	if err != nil {
+		return false, err
+	}
+	if len(specs) == 0 || specs[0].Type != "timeseries" {
+		return true, nil
+	}
+
+	var info struct {
+		VersionArray []int32 `bson:"versionArray"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return false, err
+	}
+	return majorVersion(info.VersionArray) >= timeSeriesIndexesVersion, nil
+}
+
+// majorVersion returns the major version of a server's versionArray, 0 when unknown.
+func majorVersion(version []int32) int32 {
+	if len(version) == 0 {
+		return 0
+	}
+	return version[0]
+}
+
+// messageIndexes are the indexes of the stored posts, newest first within the
+// categories, sentiment, author and tickers queried by the pipelines. Posts are
+// ordered by the time they were created, which the pipelines select them by.
+func messageIndexes(ttl time.Duration) []mongo.IndexModel {
+	storedAt := options.Index().SetName("stored_at")
+	if ttl > 0 {
+		storedAt.SetExpireAfterSeconds(int32(ttl.Seconds()))
+	}
+
+	return []mongo.IndexModel{
+		{Keys: bson.D{{Key: "stored_at", Value: 1}}, Options: storedAt},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}, Options: options.Index().SetName("time")},
+		{
+			Keys:    bson.D{{Key: "categories", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("categories_time"),
+		},
+		{
+			Keys:    bson.D{{Key: "fin_sentiment", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("fin_sentiment_time"),
+		},
+		{
+			Keys:    bson.D{{Key: "did", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("did_time"),
+		},
+		{
+			Keys:    bson.D{{Key: "tickers", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("tickers_time"),
+		},
+	}
+}
+
+// metricsIndexes are the indexes of the category and ticker metrics.
+func metricsIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "category", Value: 1}, {Key: "time", Value: -1}},
+			Options: options.Index().SetName("category_time"),
+		},
+		{
+			Keys:    bson.D{{Key: "ticker", Value: 1}, {Key: "time", Value: -1}},
+			Options: options.Index().SetName("ticker_time"),
+		},
+	}
+}
+
+// ensureIndexes creates indexes in collection, replacing an index of the same name
+// that exists with other keys or options.
+func ensureIndexes(ctx context.Context, collection *mongo.Collection, indexes []mongo.IndexModel) error {
+	for _, index := range indexes {
+		_, err := collection.Indexes().CreateOne(ctx, index)
+		if !isIndexConflict(err) {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := collection.Indexes().DropOne(ctx, *index.Options.Name); err != nil {
+			return err
+		}
+		if _, err := collection.Indexes().CreateOne(ctx, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTimeSeries creates the time-series collection name, measured at timeField,
+// unless a collection of that name exists.
+func ensureTimeSeries(ctx context.Context, db *mongo.Database, name, timeField string) error {
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": name})
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		return nil
+	}
+
+	err = db.CreateCollection(ctx, name, options.CreateCollection().SetTimeSeriesOptions(
+		options.TimeSeries().SetTimeField(timeField).SetGranularity("minutes"),
+	))
+	// Another server may have created it in the meantime.
+	if hasErrorCode(err, namespaceExists) {
+		return nil
+	}
+	return err
+}
+
+func isIndexConflict(err error) bool {
+	return hasErrorCode(err, indexOptionsConflict) || hasErrorCode(err, indexKeySpecsConflict)
+}
+
+func hasErrorCode(err error, code int) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(code)
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMessageIndexes_TTL(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want *int32
+	}{
+		{name: "Kept", ttl: 0, want: nil},
+		{name: "Expiring", ttl: 30 * 24 * time.Hour, want: ptr(int32(30 * 24 * 60 * 60))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := make(map[string]bool)
+			var storedAt *mongo.IndexModel
+			for _, index := range messageIndexes(tt.ttl) {
+				if index.Options == nil || index.Options.Name == nil {
+					t.Fatalf("Expected every index to be named, got %v", index.Keys)
+				}
+				name := *index.Options.Name
+				if names[name] {
+					t.Errorf("Expected index names to be unique, got %s twice", name)
+				}
+				names[name] = true
+				if name == "stored_at" {
+					storedAt = &index
+				}
+			}
+
+			if storedAt == nil {
+				t.Fatal("Expected an index on stored_at")
+			}
+			got := storedAt.Options.ExpireAfterSeconds
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("Expected expireAfterSeconds %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsIndexConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "None", err: nil, want: false},
+		{name: "Options", err: mongo.CommandError{Code: indexOptionsConflict}, want: true},
+		{name: "Keys", err: mongo.CommandError{Code: indexKeySpecsConflict}, want: true},
+		{name: "Other", err: mongo.CommandError{Code: namespaceExists}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isIndexConflict(tt.err); got != tt.want {
+				t.Errorf("Expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestMajorVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version []int32
+		want    int32
+	}{
+		{name: "Unknown", version: nil, want: 0},
+		{name: "5.0", version: []int32{5, 0, 26, 0}, want: 5},
+		{name: "7.0", version: []int32{7, 0, 12, 0}, want: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := majorVersion(tt.version); got != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
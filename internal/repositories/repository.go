@@ -1,10 +1,106 @@
 package repositories
 
-// Repository defines the methods for interacting with the database.
-type Repository interface {
-	Insert(data interface{}) error
-	FindAll() ([]interface{}, error)
-	FindByID(id string) (interface{}, error)
-	Update(id string, data interface{}) error
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// ErrNotFound is returned when no stored document has the requested id.
+var ErrNotFound = errors.New("document not found")
+
+// DefaultPageSize is the number of documents returned by Find when the query sets
+// no limit.
+const DefaultPageSize = 100
+
+// Repository stores values of type T in a collection.
+type Repository[T any] interface {
+	Insert(ctx context.Context, value *T) error
+	InsertMany(ctx context.Context, values []*T) error
+	FindByID(ctx context.Context, id string) (*T, error)
+	Find(ctx context.Context, query Query) (*Page[T], error)
+	Update(ctx context.Context, id string, fields map[string]any) error
+	Delete(ctx context.Context, id string) error
+}
+
+// Query selects a page of documents. Pages are ordered by id, so a collection can be
+// walked page by page while new documents are inserted.
+type Query struct {
+	// Filter holds the conditions documents must match, in the store's query
+	// language. Nil matches every document.
+	Filter map[string]any
+	// Projection lists the fields to load, every field when empty. Fields left out
+	// keep their zero value.
+	Projection []string
+	// Limit is the page size, DefaultPageSize when 0.
+	Limit int
+	// Cursor is the Next cursor of the previous page, empty for the first page.
+	Cursor string
+}
+
+// Page is a page of documents returned by Find.
+type Page[T any] struct {
+	Items []*T
+	// Next is the cursor of the following page, empty on the last page.
+	Next string
+}
+
+// BatchError reports the values of an InsertMany that were not stored, the others
+// were. Failed maps the index of each failed value to its error.
+type BatchError struct {
+	Failed map[int]error
+}
+
+func (e *BatchError) Error() string {
+	for _, err := range e.Failed {
+		return fmt.Sprintf("%d documents not stored, e.g. %v", len(e.Failed), err)
+	}
+	return "no documents failed"
+}
+
+// ValueErrors returns the error of each of n values written together by InsertMany,
+// nil for the values that were stored.
+func ValueErrors(n int, err error) []error {
+	errs := make([]error, n)
+	if err == nil {
+		return errs
+	}
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	for i, valueErr := range batchErr.Failed {
+		if i >= 0 && i < n {
+			errs[i] = valueErr
+		}
+	}
+	return errs
+}
+
+// ClassificationStore persists cached classifier results so they survive restarts
+// and can be shared between server replicas.
+type ClassificationStore interface {
+	Get(key string) (*models.Classification, bool, error)
+	Set(key string, value *models.Classification, ttl time.Duration) error
+}
+
+// DeadLetterStore keeps the messages the server failed to process until they are
+// replayed. Letters are kept by payload, so the attempts and replays of a message
+// failing again add up.
+type DeadLetterStore interface {
+	Add(letter *models.DeadLetter) error
+	// List returns the letters waiting to be replayed that were replayed fewer than
+	// maxReplays times, 0 lists them all.
+	List(limit, maxReplays int) ([]*models.DeadLetter, error)
+	// MarkReplayed records that a letter was replayed, it is only listed again when
+	// its message fails again. replayed false undoes it.
+	MarkReplayed(id string, replayed bool) error
 	Delete(id string) error
 }
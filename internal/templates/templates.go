@@ -0,0 +1,24 @@
+// Package templates parses the text/templates configured for composing classifier
+// input and routing topics.
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Parse parses a text/template rendering values like sample, kind names it in the
+// errors. References to unknown fields fail rather than render "<no value>", and are
+// caught now by rendering sample instead of on the first message.
+func Parse(kind, source string, sample any) (*template.Template, error) {
+	tmpl, err := template.New(kind).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template %q: %w", kind, source, err)
+	}
+
+	if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+		return nil, fmt.Errorf("%s template %q: %w", kind, source, err)
+	}
+	return tmpl, nil
+}
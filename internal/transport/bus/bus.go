@@ -0,0 +1,73 @@
+package bus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+	"stockseer.ai/blueksy-firehose/internal/transport/kafka"
+	"stockseer.ai/blueksy-firehose/internal/transport/mqtt"
+	"stockseer.ai/blueksy-firehose/internal/transport/nats"
+)
+
+// Backends of the message bus, selected with MESSAGE_BUS.
+const (
+	BackendMQTT  = "mqtt"
+	BackendNATS  = "nats"
+	BackendKafka = "kafka"
+
+	// BackendMemory connects the services of the all-in-one binary in process, it
+	// cannot be selected for the standalone services.
+	BackendMemory = "memory"
+)
+
+// Open connects to the message bus backend selected by MESSAGE_BUS, identifying
+// itself with id.
+func Open(appCtx appcontext.AppContext, id string) (interfaces.Bus, error) {
+	switch appCtx.Config.MessageBus {
+	case BackendMQTT:
+		client, err := mqtt.NewMqttClient(appCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case BackendNATS:
+		bus, err := nats.NewNatsBus(appCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		return bus, nil
+	case BackendKafka:
+		bus, err := kafka.NewKafkaBus(appCtx, id)
+		if err != nil {
+			return nil, err
+		}
+		return bus, nil
+	case BackendMemory:
+		return nil, fmt.Errorf("the %s message bus is only available in the all-in-one binary", BackendMemory)
+	}
+	return nil, fmt.Errorf("unknown message bus %q", appCtx.Config.MessageBus)
+}
+
+// clientIDFor returns the client id of a component. Every component and replica needs
+// its own id, an MQTT broker disconnects the older client when a second one connects
+// with the same id, so the component is appended to BUS_CLIENT_ID, and without one
+// the host name too. Host names are stable across container restarts, which
+// persistent sessions rely on.
+func clientIDFor(cfg *config.AppConfig, component string) string {
+	if cfg.BusClientID != "" {
+		return cfg.BusClientID + "-" + component
+	}
+
+	suffix, err := os.Hostname()
+	if err != nil || suffix == "" {
+		b := make([]byte, 4)
+		_, _ = rand.Read(b)
+		suffix = hex.EncodeToString(b)
+	}
+	return "bluesky-client-" + cfg.MQTTUsername + "-" + component + "-" + suffix
+}
@@ -0,0 +1,24 @@
+package bus
+
+import (
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+)
+
+func TestClientIDFor(t *testing.T) {
+	cfg := &config.AppConfig{MQTTUsername: "guest"}
+
+	id := clientIDFor(cfg, "server")
+	if id == "bluesky-client-guest-server" || id != clientIDFor(cfg, "server") {
+		t.Errorf("Expected a stable per host client id, got %q", id)
+	}
+
+	cfg.BusClientID = "replica-1"
+	if id := clientIDFor(cfg, "server"); id != "replica-1-server" {
+		t.Errorf("Expected the configured client id with the component, got %q", id)
+	}
+	if clientIDFor(cfg, "wss") == clientIDFor(cfg, "server") {
+		t.Error("Expected the components of a replica to have distinct client ids")
+	}
+}
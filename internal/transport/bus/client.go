@@ -0,0 +1,418 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/domain"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/repositories"
+)
+
+// Client publishes posts and metrics to the message bus and, in the server,
+// classifies and stores the posts consumed from it.
+type Client struct {
+	bus        interfaces.Bus
+	appCtx     appcontext.AppContext
+	processors *domain.TextProcessorFactory
+	config     *config.AppConfig
+
+	mu            sync.Mutex
+	dataCollector *domain.DataCollector
+	pool          *workerPool                                    // Processes received messages, nil for publishers
+	writer        *repositories.BatchWriter[models.ProtoMessage] // Batches stored messages, nil when disabled
+	spool         *Spool                                         // Buffers published messages while disconnected
+	router        *TopicRouter                                   // Republishes classified messages, nil without routes
+	metricsTopic  string                                         // The client's subtopic of the metrics topic
+	drainCh       chan struct{}
+
+	// Closed once the client is disconnected, which ends ConsumeMessages.
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+// Ensure Client implements the interfaces.MessageClient interface.
+var _ interfaces.MessageClient = (*Client)(nil)
+
+// NewClient connects to the message bus selected by MESSAGE_BUS. component names the
+// service in the client id, processors are nil for clients that only publish.
+func NewClient(
+	appCtx appcontext.AppContext,
+	component string,
+	processors *domain.TextProcessorFactory,
+) (*Client, error) {
+	cfg := &appCtx.Config
+	if cfg.MessageBus == BackendMQTT && !cfg.MQTTEnabled {
+		appCtx.Log.Info("MQTT is disabled in configuration")
+		return nil, nil
+	}
+
+	if err := validPayloadFormat(cfg.BusPayloadFormat); err != nil {
+		return nil, err
+	}
+	if err := validDeadLetterSink(cfg); err != nil {
+		return nil, err
+	}
+
+	id := clientIDFor(cfg, component)
+	bus, err := Open(appCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(appCtx, bus, id, processors)
+}
+
+// NewClientWithBus creates a client over an already open bus, such as the in-memory
+// bus connecting the services of the all-in-one binary. The client closes the bus
+// when disconnected.
+func NewClientWithBus(
+	appCtx appcontext.AppContext,
+	bus interfaces.Bus,
+	component string,
+	processors *domain.TextProcessorFactory,
+) (*Client, error) {
+	cfg := &appCtx.Config
+	if err := validPayloadFormat(cfg.BusPayloadFormat); err != nil {
+		return nil, err
+	}
+	if err := validDeadLetterSink(cfg); err != nil {
+		return nil, err
+	}
+	return newClient(appCtx, bus, clientIDFor(cfg, component), processors)
+}
+
+func newClient(
+	appCtx appcontext.AppContext,
+	bus interfaces.Bus,
+	id string,
+	processors *domain.TextProcessorFactory,
+) (*Client, error) {
+	c := &Client{
+		bus:           bus,
+		appCtx:        appCtx,
+		processors:    processors,
+		config:        &appCtx.Config,
+		dataCollector: domain.NewDataCollector(appCtx),
+		drainCh:       make(chan struct{}, 1),
+		stopped:       make(chan struct{}),
+	}
+
+	// Every client publishes its metrics to its own subtopic, so retained snapshots of
+	// replicas do not replace each other.
+	c.metricsTopic = c.config.BusMetricsTopic + "/" + id
+
+	if processors != nil && processors.Cache() != nil {
+		c.dataCollector.WatchCache(processors.Cache())
+	}
+
+	if processors != nil {
+		if c.config.BusWorkers < 1 || c.config.BusQueueSize < 0 {
+			return nil, fmt.Errorf(
+				"invalid message worker pool: %d workers, queue size %d",
+				c.config.BusWorkers,
+				c.config.BusQueueSize,
+			)
+		}
+		c.pool = newWorkerPool(c.config.BusWorkers, c.config.BusQueueSize, c.handleMessage)
+		c.dataCollector.WatchQueue(c.pool.Stats)
+
+		if c.config.MongoBatchSize > 0 && appCtx.MessageRepo != nil {
+			c.writer = repositories.NewBatchWriter(
+				appCtx.MessageRepo,
+				c.config.MongoBatchSize,
+				c.config.MongoBatchMaxAge,
+				c.config.MongoWriteQueueSize,
+			)
+			c.dataCollector.WatchWrites(c.writer.Stats)
+		}
+	}
+
+	if len(c.config.BusRouteTemplates) > 0 {
+		router, err := NewTopicRouter(c.config.BusRouteTemplates)
+		if err != nil {
+			return nil, err
+		}
+		c.router = router
+	}
+
+	// Start metrics collection once when the client is initialized. Only clients
+	// processing messages have any to report.
+	if processors != nil {
+		c.dataCollector.PublishTo(c.PublishMetrics)
+		if metricsErr := c.dataCollector.StartMetrics(appCtx, true); metricsErr != nil {
+			appCtx.Log.Error("failed to start metrics collection...", metricsErr)
+		}
+	}
+
+	appCtx.Log.Info("Message Bus: %s", c.config.MessageBus)
+	appCtx.Log.Info("Client ID: %s", id)
+	appCtx.Log.Info("Metrics Topic: %s", c.metricsTopic)
+	appCtx.Log.Info("Messages Topic: %s", c.config.BusMessagesTopic)
+	appCtx.Log.Info("Payload Format: %s", c.config.BusPayloadFormat)
+	appCtx.Log.Info("Dead Letter Sink: %s", c.config.DeadLetterSink)
+
+	return c, nil
+}
+
+// IsConnected reports whether messages can currently be published.
+func (c *Client) IsConnected() bool {
+	return c.bus.IsConnected()
+}
+
+// Disconnect processes the queued messages and writes the buffered ones, waiting up
+// to BUS_DRAIN_TIMEOUT for each, and disconnects from the message bus.
+func (c *Client) Disconnect() {
+	if c.pool != nil {
+		c.appCtx.Log.Info("Draining message queue...")
+		if err := c.pool.Close(c.config.BusDrainTimeout); err != nil {
+			c.appCtx.Log.Error("Failed to drain message queue", err)
+		}
+	}
+	if c.writer != nil {
+		c.appCtx.Log.Info("Flushing buffered writes...")
+		if err := c.writer.Close(c.config.BusDrainTimeout); err != nil {
+			c.appCtx.Log.Error("Failed to flush buffered writes", err)
+		}
+	}
+	defer c.stopOnce.Do(func() { close(c.stopped) })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.bus.Close(); err != nil {
+		c.appCtx.Log.Error("Failed to disconnect from the message bus", err)
+	}
+
+	if c.spool != nil {
+		if err := c.spool.Close(); err != nil {
+			c.appCtx.Log.Error("Failed to close message spool", err)
+		}
+	}
+}
+
+// PublishMessage publishes a post to the messages topic in the configured payload
+// format, or formats. With a spool it is spooled while the broker is unreachable.
+func (c *Client) PublishMessage(msg *models.ProtoMessage) error {
+	for _, format := range publishFormats(c.config.BusPayloadFormat) {
+		topic, data, err := encodePayload(c.config.BusMessagesTopic, format, msg)
+		if err != nil {
+			return err
+		}
+		if err := c.spoolOrPublish(topic, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishMetrics publishes the metrics of an interval, as JSON, to the client's
+// subtopic of the metrics topic.
+func (c *Client) PublishMetrics(metrics *models.IntervalMetrics) error {
+	data, err := metrics.ToJSON()
+	if err != nil {
+		return err
+	}
+	return c.publish(c.metricsTopic, []byte(data))
+}
+
+func (c *Client) publish(topic string, data []byte) error {
+	return c.bus.Publish(topic, data)
+}
+
+// processMessage parses, classifies and stores a received message, then calls done
+// with the processed message, or nil when it could not be parsed or was dead lettered.
+// Failing stages are retried, then the message is dead lettered when a sink is
+// configured. A message that failed without being dead lettered is passed to done
+// with its error instead. With batched writes done is called once the message's batch
+// is written.
+func (c *Client) processMessage(msg interfaces.BusMessage, done func(*models.ProtoMessage, error)) {
+	protoMessage, err := decodePayload(msg.Topic(), msg.Payload())
+	if err != nil {
+		c.appCtx.Log.Error("Error parsing message", err)
+		// Delivering it again would not help, so it is done with even without a sink.
+		c.deadLetter(msg, models.DeadLetterStageParse, 1, err)
+		done(nil, nil)
+		return
+	}
+
+	results, attempts, err := c.processors.ProcessMessageWith(protoMessage, func(fn func() error) (int, error) {
+		return retry(c.config.DeadLetterMaxAttempts, fn)
+	})
+	if err != nil {
+		c.appCtx.Log.Error("Failed to process message", err)
+		// A dead lettered message is replayed whole, storing it now would store it twice.
+		if c.deadLetter(msg, models.DeadLetterStageClassify, attempts, err) {
+			err = nil
+		}
+		done(nil, err)
+		return
+	}
+	domain.ApplyClassifications(protoMessage, results)
+	if err := c.processors.EnrichAll(protoMessage); err != nil {
+		c.appCtx.Log.Error("Failed to enrich message", err)
+	}
+
+	if !domain.ShouldStoreMessage(protoMessage) {
+		done(protoMessage, nil)
+		return
+	}
+	c.storeMessage(msg, protoMessage, done)
+}
+
+// storeMessage stores protoMessage and calls done with the result of insertMessage.
+// With batched writes the message is queued for the next batch and only retried on
+// its own when its batch failed to store it.
+func (c *Client) storeMessage(
+	msg interfaces.BusMessage,
+	protoMessage *models.ProtoMessage,
+	done func(*models.ProtoMessage, error),
+) {
+	if c.writer == nil {
+		done(c.insertMessage(msg, protoMessage, 0, nil))
+		return
+	}
+
+	err := c.writer.Write(protoMessage, func(err error) {
+		if err != nil {
+			c.appCtx.Log.Error("Failed to insert message in batch", err)
+		}
+		done(c.insertMessage(msg, protoMessage, 1, err))
+	})
+	if err != nil {
+		// Shutting down, the message is left unacknowledged for redelivery.
+		c.appCtx.Log.Error("Failed to queue message for storage", err)
+	}
+}
+
+// insertMessage makes the attempts left to store protoMessage after the attempts
+// already made, the last of which failed with err, and dead letters it when they
+// all fail. It returns protoMessage, nil when it was dead lettered, or the error
+// when it could not be dead lettered either.
+func (c *Client) insertMessage(
+	msg interfaces.BusMessage,
+	protoMessage *models.ProtoMessage,
+	attempts int,
+	err error,
+) (*models.ProtoMessage, error) {
+	if attempts == 0 || err != nil && attempts < c.config.DeadLetterMaxAttempts {
+		var retried int
+		retried, err = retry(c.config.DeadLetterMaxAttempts-attempts, func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), insertTimeout)
+			defer cancel()
+			return c.appCtx.MessageRepo.Insert(ctx, protoMessage)
+		})
+		attempts += retried
+	}
+	if err == nil {
+		return protoMessage, nil
+	}
+
+	c.appCtx.Log.Error("Failed to insert message", err)
+	if c.deadLetter(msg, models.DeadLetterStageStore, attempts, err) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// handleMessage processes msg and acknowledges it once processed. Dead lettered
+// messages are acknowledged too; those that failed without being dead lettered are
+// left unacknowledged, so the broker delivers them again.
+func (c *Client) handleMessage(msg interfaces.BusMessage) {
+	c.processMessage(msg, func(protoMessage *models.ProtoMessage, err error) {
+		if err != nil {
+			c.appCtx.Log.Warn("Leaving message from %s unacknowledged for redelivery", msg.Topic())
+			return
+		}
+		defer msg.Ack()
+
+		if protoMessage == nil {
+			return
+		}
+		if err := c.dataCollector.Add(protoMessage); err != nil {
+			c.appCtx.Log.Error("Failed to add message to data collector", err)
+		}
+		if err := c.routeMessage(protoMessage); err != nil {
+			c.appCtx.Log.Error("Failed to route message", err)
+		}
+	})
+}
+
+// routeMessage republishes a classified message, as JSON, to the topics derived
+// from it by the routing templates.
+func (c *Client) routeMessage(msg *models.ProtoMessage) error {
+	if c.router == nil {
+		return nil
+	}
+
+	topics, err := c.router.Topics(msg)
+	if err != nil || len(topics) == 0 {
+		return err
+	}
+
+	data, err := msg.ToJSON()
+	if err != nil {
+		return err
+	}
+	for _, topic := range topics {
+		if err := c.publish(topic, []byte(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// messageHandler hands received messages to the worker pool, it blocks while the
+// pool's queue is full.
+func (c *Client) messageHandler(msg interfaces.BusMessage) {
+	if c.pool == nil {
+		c.handleMessage(msg)
+		return
+	}
+	if !c.pool.Submit(msg) {
+		// Shutting down, the message is left unacknowledged for redelivery.
+		c.appCtx.Log.Debug("Message queue closed, not processing message from %s", msg.Topic())
+	}
+}
+
+// waitConnected blocks until the bus is connected or timeout passes.
+func (c *Client) waitConnected(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for !c.bus.IsConnected() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the message bus connection")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+// Subscribe subscribes to the messages topic, the messages are processed by the
+// worker pool until the client is disconnected.
+func (c *Client) Subscribe() error {
+	topic := consumeTopic(c.config.BusMessagesTopic, c.config.BusPayloadFormat)
+
+	if err := c.bus.Subscribe(topic, c.messageHandler); err != nil {
+		return fmt.Errorf("failed to register message consumption for topic %s: %w", topic, err)
+	}
+	c.appCtx.Log.Info("Message consumption set up for topic: %s", topic)
+	return nil
+}
+
+// ConsumeMessages subscribes to the messages topic and processes the messages until
+// the client is disconnected.
+func (c *Client) ConsumeMessages() error {
+	if err := c.Subscribe(); err != nil {
+		return err
+	}
+
+	// Keep the function running to listen for messages until the client is
+	// disconnected. It's important that this is called in a goroutine if your main
+	// function needs to do other things.
+	<-c.stopped
+	return nil
+}
@@ -0,0 +1,89 @@
+package bus
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// Payload formats of the messages topic. MQTT v3 has no content type, so the
+// format is carried by the topic: JSON on the configured topic, which existing
+// consumers keep reading, and binary protobuf on the same topic with a "/pb" suffix.
+const (
+	PayloadFormatJSON     = "json"
+	PayloadFormatProtobuf = "protobuf"
+	// PayloadFormatBoth publishes both encodings, to migrate consumers one at a time.
+	PayloadFormatBoth = "both"
+
+	protobufTopicSuffix = "/pb"
+)
+
+func validPayloadFormat(format string) error {
+	switch format {
+	case PayloadFormatJSON, PayloadFormatProtobuf, PayloadFormatBoth:
+		return nil
+	}
+	return fmt.Errorf("unknown MQTT payload format %q", format)
+}
+
+// protobufTopic returns the topic carrying the protobuf encoding of topic's messages.
+func protobufTopic(topic string) string {
+	return topic + protobufTopicSuffix
+}
+
+// publishFormats returns the encodings a publisher sends for format.
+func publishFormats(format string) []string {
+	switch format {
+	case PayloadFormatProtobuf:
+		return []string{PayloadFormatProtobuf}
+	case PayloadFormatBoth:
+		return []string{PayloadFormatJSON, PayloadFormatProtobuf}
+	}
+	return []string{PayloadFormatJSON}
+}
+
+// consumeTopic returns the topic a consumer reads for format. A consumer configured
+// with both formats reads protobuf, reading both would process every message twice.
+func consumeTopic(topic string, format string) string {
+	if format == PayloadFormatJSON {
+		return topic
+	}
+	return protobufTopic(topic)
+}
+
+// encodePayload serializes msg for the topic of format.
+func encodePayload(topic string, format string, msg *models.ProtoMessage) (string, []byte, error) {
+	if format == PayloadFormatProtobuf {
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return "", nil, fmt.Errorf("error marshaling protobuf message: %w", err)
+		}
+		return protobufTopic(topic), data, nil
+	}
+
+	data, err := msg.ToJSON()
+	if err != nil {
+		return "", nil, err
+	}
+	return topic, []byte(data), nil
+}
+
+// decodePayload parses a message received on topic, using the encoding the topic carries.
+func decodePayload(topic string, payload []byte) (*models.ProtoMessage, error) {
+	var msg models.ProtoMessage
+
+	if strings.HasSuffix(topic, protobufTopicSuffix) {
+		if err := proto.Unmarshal(payload, &msg); err != nil {
+			return nil, fmt.Errorf("error parsing protobuf message: %w", err)
+		}
+		return &msg, nil
+	}
+
+	if err := protojson.Unmarshal(payload, &msg); err != nil {
+		return nil, fmt.Errorf("error parsing JSON message: %w", err)
+	}
+	return &msg, nil
+}
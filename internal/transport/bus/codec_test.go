@@ -0,0 +1,141 @@
+package bus
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+func sampleMessage() *models.ProtoMessage {
+	uri := "https://www.reuters.com/markets/us/fed-holds-rates-steady-2025-06-18/"
+	title := "Fed holds rates steady, still sees two cuts this year"
+	description := "The Federal Reserve held interest rates steady on Wednesday."
+	did := "did:plc:z72i7hdynmk6r22z27h6tvur"
+	sentiment := "negative"
+
+	return &models.ProtoMessage{
+		Did:    did,
+		TimeUs: 1750262400000000,
+		Kind:   "commit",
+		Commit: &models.Commit{
+			Rev:        "3lrw5gk2bxs2p",
+			Operation:  "create",
+			Collection: "app.bsky.feed.post",
+			Rkey:       "3lrw5gjyxzk2p",
+			Cid:        "bafyreihd3vcjlnqg5g4lw5j6r7scj5rf3xgfnfbrqnnvnh6ycphx7kq7iu",
+			Record: &models.Record{
+				Type:      "app.bsky.feed.post",
+				CreatedAt: "2025-06-18T18:00:00.000Z",
+				Langs:     []string{"en"},
+				Text: "Fed holds rates steady as expected, markets slip on the outlook for fewer cuts. " +
+					"$SPY $QQQ reuters.com/markets/us/fed-holds…",
+				Facets: []*models.Facet{
+					{
+						Index:    &models.Index{ByteStart: 96, ByteEnd: 130},
+						Features: []*models.Feature{{Uri: &uri}},
+					},
+				},
+				Embed: &models.Embed{
+					Type: "app.bsky.embed.external",
+					External: &models.EmbedExternal{
+						Uri:         &uri,
+						Title:       &title,
+						Description: &description,
+					},
+				},
+			},
+		},
+		Categories:   []string{"economy", "business", "finance"},
+		FinSentiment: &sentiment,
+		Tickers:      []string{"SPY", "QQQ"},
+		Classifications: map[string]*models.Classification{
+			"fin_sentiment": {
+				Classifier:   "TextFinSentimentClassifier",
+				Labels:       []string{"negative"},
+				Scores:       []float64{0.91},
+				ModelVersion: "ahmedrachid/FinancialBERT-Sentiment-Analysis",
+				LatencyUs:    18250,
+			},
+		},
+	}
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	msg := sampleMessage()
+
+	for _, format := range []string{PayloadFormatJSON, PayloadFormatProtobuf} {
+		t.Run(format, func(t *testing.T) {
+			topic, data, err := encodePayload("messages", format, msg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expectedTopic := "messages"
+			if format == PayloadFormatProtobuf {
+				expectedTopic = "messages/pb"
+			}
+			if topic != expectedTopic {
+				t.Errorf("Expected topic %q, got %q", expectedTopic, topic)
+			}
+
+			decoded, err := decodePayload(topic, data)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !proto.Equal(msg, decoded) {
+				t.Errorf("Round trip changed the message:\n%v\n%v", msg, decoded)
+			}
+		})
+	}
+}
+
+func TestPayloadFormats(t *testing.T) {
+	if err := validPayloadFormat("xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if formats := publishFormats(PayloadFormatBoth); len(formats) != 2 {
+		t.Errorf("Expected both formats to be published, got %v", formats)
+	}
+	if topic := consumeTopic("messages", PayloadFormatBoth); topic != "messages/pb" {
+		t.Errorf("Expected consumers of both formats to read protobuf, got %q", topic)
+	}
+	if topic := consumeTopic("messages", PayloadFormatJSON); topic != "messages" {
+		t.Errorf("Expected JSON consumers to read the configured topic, got %q", topic)
+	}
+}
+
+func benchmarkEncode(b *testing.B, format string) {
+	msg := sampleMessage()
+	var size int
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_, data, err := encodePayload("messages", format, msg)
+		if err != nil {
+			b.Fatal(err)
+		}
+		size = len(data)
+	}
+	b.ReportMetric(float64(size), "bytes/msg")
+}
+
+func benchmarkDecode(b *testing.B, format string) {
+	topic, data, err := encodePayload("messages", format, sampleMessage())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		if _, err := decodePayload(topic, data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeJSON(b *testing.B)     { benchmarkEncode(b, PayloadFormatJSON) }
+func BenchmarkEncodeProtobuf(b *testing.B) { benchmarkEncode(b, PayloadFormatProtobuf) }
+func BenchmarkDecodeJSON(b *testing.B)     { benchmarkDecode(b, PayloadFormatJSON) }
+func BenchmarkDecodeProtobuf(b *testing.B) { benchmarkDecode(b, PayloadFormatProtobuf) }
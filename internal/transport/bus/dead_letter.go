@@ -0,0 +1,215 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+	"stockseer.ai/blueksy-firehose/internal/models"
+)
+
+// retryBackoff is the wait before the second attempt of a failing stage, doubled
+// after every further attempt.
+var retryBackoff = 200 * time.Millisecond
+
+// insertTimeout bounds each attempt to store a message on its own, so a stalled
+// database fails the attempt instead of holding the message's worker indefinitely.
+var insertTimeout = 10 * time.Second
+
+func validDeadLetterSink(cfg *config.AppConfig) error {
+	switch cfg.DeadLetterSink {
+	case "", models.DeadLetterSinkMongo:
+		return nil
+	case models.DeadLetterSinkMQTT:
+		if cfg.DeadLetterTopic == "" {
+			return fmt.Errorf("DEAD_LETTER_TOPIC is required with the mqtt dead letter sink")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown dead letter sink %q", cfg.DeadLetterSink)
+}
+
+// retry runs fn until it succeeds or maxAttempts is reached and returns the number
+// of attempts made with the last error.
+func retry(maxAttempts int, fn func() error) (int, error) {
+	backoff := retryBackoff
+	attempt := 1
+	for {
+		err := fn()
+		if err == nil || attempt >= maxAttempts {
+			return attempt, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		attempt++
+	}
+}
+
+// deadLetter sends a message that failed stage to the configured sink and reports
+// whether it was kept there. Without a sink the failure is only logged, as before.
+func (c *Client) deadLetter(msg interfaces.BusMessage, stage string, attempts int, err error) bool {
+	letter := models.NewDeadLetter(msg.Topic(), msg.Payload(), stage, attempts, err)
+
+	var sinkErr error
+	switch c.config.DeadLetterSink {
+	case models.DeadLetterSinkMQTT:
+		var data string
+		if data, sinkErr = letter.ToJSON(); sinkErr == nil {
+			sinkErr = c.publish(c.config.DeadLetterTopic, []byte(data))
+		}
+	case models.DeadLetterSinkMongo:
+		sinkErr = c.appCtx.DeadLetterStore.Add(letter)
+	default:
+		return false
+	}
+
+	if sinkErr != nil {
+		c.appCtx.Log.Error("Failed to dead letter message at stage %s", sinkErr, stage)
+		return false
+	}
+	c.appCtx.Log.Info(
+		"Dead lettered message %s at stage %s after %d attempts",
+		letter.ID,
+		stage,
+		attempts,
+	)
+	return true
+}
+
+// ReplayDeadLetters publishes up to limit dead-lettered messages (all of them when
+// limit is 0) back to the topic they were received on, so the servers process them
+// again. Messages replayed DEAD_LETTER_MAX_REPLAYS times are kept in the sink and no
+// longer replayed, so a message that always fails does not loop. Messages on a dead
+// letter topic are read until none arrived for idle.
+func (c *Client) ReplayDeadLetters(limit int, idle time.Duration) (int, error) {
+	if err := validDeadLetterSink(c.config); err != nil {
+		return 0, err
+	}
+	if err := c.waitConnected(30 * time.Second); err != nil {
+		return 0, err
+	}
+
+	switch c.config.DeadLetterSink {
+	case models.DeadLetterSinkMongo:
+		return c.replayFromStore(limit)
+	case models.DeadLetterSinkMQTT:
+		return c.replayFromTopic(limit, idle)
+	}
+	return 0, fmt.Errorf("no dead letter sink configured")
+}
+
+// exhausted reports whether letter was replayed as often as it may be.
+func (c *Client) exhausted(letter *models.DeadLetter) bool {
+	if c.config.DeadLetterMaxReplays <= 0 || letter.Replays < c.config.DeadLetterMaxReplays {
+		return false
+	}
+	c.appCtx.Log.Warn(
+		"Not replaying dead letter %s, it failed again after %d replays and %d attempts",
+		letter.ID,
+		letter.Replays,
+		letter.Attempts,
+	)
+	return true
+}
+
+// replayFromStore publishes the stored letters. A letter is marked replayed before it
+// is published, so it is listed again if its message fails before the mark is stored.
+func (c *Client) replayFromStore(limit int) (int, error) {
+	store := c.appCtx.DeadLetterStore
+	letters, err := store.List(limit, c.config.DeadLetterMaxReplays)
+	if err != nil {
+		return 0, fmt.Errorf("listing dead letters: %w", err)
+	}
+
+	replayed := 0
+	for _, letter := range letters {
+		if err := store.MarkReplayed(letter.ID, true); err != nil {
+			return replayed, fmt.Errorf("marking dead letter %s replayed: %w", letter.ID, err)
+		}
+		if err := c.publish(letter.Topic, letter.Payload); err != nil {
+			if undoErr := store.MarkReplayed(letter.ID, false); undoErr != nil {
+				c.appCtx.Log.Error("Failed to keep dead letter %s for the next replay", undoErr, letter.ID)
+			}
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// replayFromTopic reads the dead letter topic. A message failing again while the
+// replay runs comes back on the topic, it is returned with the attempts and replays
+// of its earlier letter and replaying stops there so it does not loop. Letters
+// replayed too often are returned to the topic as they are.
+func (c *Client) replayFromTopic(limit int, idle time.Duration) (int, error) {
+	topic := c.config.DeadLetterTopic
+	letters := make(chan *models.DeadLetter)
+	done := make(chan struct{})
+	defer close(done)
+
+	handler := func(msg interfaces.BusMessage) {
+		defer msg.Ack()
+
+		var letter models.DeadLetter
+		if err := json.Unmarshal(msg.Payload(), &letter); err != nil {
+			c.appCtx.Log.Error("Skipping invalid dead letter", err)
+			return
+		}
+		select {
+		case letters <- &letter:
+		case <-done:
+			// Received after the replay ended, return it to the topic. It is not waited
+			// for, a handler waiting for a publish can stall the client.
+			go func(payload []byte) {
+				if err := c.publish(topic, payload); err != nil {
+					c.appCtx.Log.Error("Failed to return dead letter to %s", err, topic)
+				}
+			}(msg.Payload())
+		}
+	}
+	if err := c.bus.Subscribe(topic, handler); err != nil {
+		return 0, err
+	}
+	defer c.bus.Unsubscribe(topic)
+
+	// Letters seen in this run, the replayed ones with their letter before the replay.
+	seen := make(map[string]*models.DeadLetter)
+	replayed := 0
+	for limit == 0 || replayed < limit {
+		select {
+		case letter := <-letters:
+			if earlier, ok := seen[letter.ID]; ok {
+				if earlier != nil {
+					letter.FailedAgain(earlier)
+				}
+				return replayed, c.returnLetter(letter)
+			}
+			if c.exhausted(letter) {
+				seen[letter.ID] = nil
+				if err := c.returnLetter(letter); err != nil {
+					return replayed, err
+				}
+				continue
+			}
+			if err := c.publish(letter.Topic, letter.Payload); err != nil {
+				return replayed, err
+			}
+			seen[letter.ID] = letter
+			replayed++
+		case <-time.After(idle):
+			return replayed, nil
+		}
+	}
+	return replayed, nil
+}
+
+// returnLetter publishes letter back to the dead letter topic.
+func (c *Client) returnLetter(letter *models.DeadLetter) error {
+	data, err := letter.ToJSON()
+	if err != nil {
+		return err
+	}
+	return c.publish(c.config.DeadLetterTopic, []byte(data))
+}
@@ -0,0 +1,358 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/domain"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+	"stockseer.ai/blueksy-firehose/internal/logger"
+	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/repositories"
+	"stockseer.ai/blueksy-firehose/internal/transport/memory"
+)
+
+type fakeMessage struct {
+	topic   string
+	payload []byte
+}
+
+func (m *fakeMessage) Topic() string   { return m.topic }
+func (m *fakeMessage) Payload() []byte { return m.payload }
+func (m *fakeMessage) Ack()            {}
+
+// memoryDeadLetterStore keeps letters by id like the Mongo store.
+type memoryDeadLetterStore struct {
+	letters  []*models.DeadLetter
+	replayed map[string]bool
+}
+
+func (s *memoryDeadLetterStore) Add(letter *models.DeadLetter) error {
+	for _, stored := range s.letters {
+		if stored.ID == letter.ID {
+			stored.Stage, stored.Reason = letter.Stage, letter.Reason
+			stored.Attempts += letter.Attempts
+			delete(s.replayed, letter.ID)
+			return nil
+		}
+	}
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func (s *memoryDeadLetterStore) List(limit, maxReplays int) ([]*models.DeadLetter, error) {
+	var letters []*models.DeadLetter
+	for _, letter := range s.letters {
+		if limit > 0 && len(letters) == limit {
+			break
+		}
+		if !s.replayed[letter.ID] && (maxReplays <= 0 || letter.Replays < maxReplays) {
+			copied := *letter
+			letters = append(letters, &copied)
+		}
+	}
+	return letters, nil
+}
+
+func (s *memoryDeadLetterStore) MarkReplayed(id string, replayed bool) error {
+	if s.replayed == nil {
+		s.replayed = make(map[string]bool)
+	}
+	for _, letter := range s.letters {
+		if letter.ID == id && replayed != s.replayed[id] {
+			s.replayed[id] = replayed
+			if replayed {
+				letter.Replays++
+			} else {
+				letter.Replays--
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memoryDeadLetterStore) Delete(id string) error {
+	return nil
+}
+
+type fakeRepository struct {
+	err      error
+	inserted int
+}
+
+func (r *fakeRepository) Insert(ctx context.Context, msg *models.ProtoMessage) error {
+	r.inserted++
+	return r.err
+}
+
+func (r *fakeRepository) InsertMany(ctx context.Context, msgs []*models.ProtoMessage) error {
+	r.inserted += len(msgs)
+	return r.err
+}
+
+func (r *fakeRepository) FindByID(ctx context.Context, id string) (*models.ProtoMessage, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func (r *fakeRepository) Find(ctx context.Context, query repositories.Query) (*repositories.Page[models.ProtoMessage], error) {
+	return &repositories.Page[models.ProtoMessage]{}, nil
+}
+
+func (r *fakeRepository) Update(ctx context.Context, id string, fields map[string]any) error {
+	return nil
+}
+func (r *fakeRepository) Delete(ctx context.Context, id string) error { return nil }
+
+// flakyClassifier fails its first failures calls.
+type flakyClassifier struct {
+	failures int
+	calls    int
+}
+
+func (c *flakyClassifier) Name() string { return "flaky" }
+
+func (c *flakyClassifier) Classify(text string) (*domain.ClassificationResult, error) {
+	c.calls++
+	if c.calls <= c.failures {
+		return nil, errors.New("classifier unavailable")
+	}
+	return &domain.ClassificationResult{
+		Labels: []domain.LabelScore{{Label: "negative", Score: 0.9}},
+	}, nil
+}
+
+func TestProcessMessage_DeadLetters(t *testing.T) {
+	retryBackoff = 0
+
+	post := sampleMessage()
+	post.FinSentiment = nil
+	post.Classifications = nil
+	_, payload, err := encodePayload("messages", PayloadFormatProtobuf, post)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name          string
+		payload       []byte
+		failures      int
+		insertErr     error
+		batched       bool
+		noSink        bool
+		stage         string
+		attempts      int
+		expectedStore int
+	}{
+		{name: "Unparsable", payload: []byte("{"), stage: models.DeadLetterStageParse, attempts: 1},
+		{
+			name:     "Classifier down",
+			payload:  payload,
+			failures: 5,
+			stage:    models.DeadLetterStageClassify,
+			attempts: 3,
+		},
+		{name: "Classifier recovers", payload: payload, failures: 2, expectedStore: 1},
+		{
+			name:          "Mongo down",
+			payload:       payload,
+			insertErr:     errors.New("no reachable servers"),
+			stage:         models.DeadLetterStageStore,
+			attempts:      3,
+			expectedStore: 3,
+		},
+		{
+			// Without a sink the message is left for the broker to deliver again.
+			name:          "Mongo down, no sink",
+			payload:       payload,
+			insertErr:     errors.New("no reachable servers"),
+			noSink:        true,
+			expectedStore: 3,
+		},
+		{name: "Batched", payload: payload, batched: true, expectedStore: 1},
+		{
+			// The batch is the first attempt, the others are made on their own.
+			name:          "Batched, Mongo down",
+			payload:       payload,
+			insertErr:     errors.New("no reachable servers"),
+			batched:       true,
+			stage:         models.DeadLetterStageStore,
+			attempts:      3,
+			expectedStore: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &memoryDeadLetterStore{}
+			repo := &fakeRepository{err: tc.insertErr}
+			processors := domain.NewTextProcessorFactory()
+			processors.AddProcessor("fin_sentiment", 0, &flakyClassifier{failures: tc.failures})
+
+			c := &Client{
+				appCtx: appcontext.AppContext{
+					Config: config.AppConfig{
+						DeadLetterSink:        models.DeadLetterSinkMongo,
+						DeadLetterMaxAttempts: 3,
+					},
+					Log:             logger.NewLogger(),
+					MessageRepo:     repo,
+					DeadLetterStore: store,
+				},
+				processors: processors,
+			}
+			c.config = &c.appCtx.Config
+			if tc.noSink {
+				c.config.DeadLetterSink = ""
+			}
+			if tc.batched {
+				c.writer = repositories.NewBatchWriter[models.ProtoMessage](repo, 10, time.Millisecond, 10)
+				defer c.writer.Close(time.Second)
+			}
+
+			processed := make(chan *models.ProtoMessage, 1)
+			failed := make(chan error, 1)
+			c.processMessage(
+				&fakeMessage{topic: "messages/pb", payload: tc.payload},
+				func(msg *models.ProtoMessage, err error) {
+					failed <- err
+					processed <- msg
+				},
+			)
+			var result *models.ProtoMessage
+			var err error
+			select {
+			case err = <-failed:
+				result = <-processed
+			case <-time.After(5 * time.Second):
+				t.Fatal("Timed out waiting for the message to be processed")
+			}
+
+			if repo.inserted != tc.expectedStore {
+				t.Errorf("Expected %d inserts, got %d", tc.expectedStore, repo.inserted)
+			}
+			if tc.noSink {
+				if err == nil || result != nil {
+					t.Errorf("Expected the failure to be returned without the message, got %v and %v", err, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tc.stage == "" {
+				if result == nil || len(store.letters) != 0 {
+					t.Fatalf("Expected the message to be processed, got %d dead letters", len(store.letters))
+				}
+				return
+			}
+
+			if result != nil {
+				t.Error("Expected a dead lettered message not to be returned")
+			}
+			if len(store.letters) != 1 {
+				t.Fatalf("Expected 1 dead letter, got %d", len(store.letters))
+			}
+			letter := store.letters[0]
+			if letter.Stage != tc.stage || letter.Attempts != tc.attempts {
+				t.Errorf("Expected stage %s after %d attempts, got %+v", tc.stage, tc.attempts, letter)
+			}
+			if letter.Topic != "messages/pb" || string(letter.Payload) != string(tc.payload) ||
+				letter.Reason == "" {
+				t.Errorf("Expected the received payload and reason to be kept, got %+v", letter)
+			}
+		})
+	}
+}
+
+func TestReplayFromStore_MaxReplays(t *testing.T) {
+	failing := models.NewDeadLetter("messages/pb", []byte("failing"), models.DeadLetterStageStore, 3, errors.New("down"))
+	poison := models.NewDeadLetter("messages/pb", []byte("poison"), models.DeadLetterStageClassify, 3, errors.New("bad"))
+	poison.Replays = 2
+	store := &memoryDeadLetterStore{letters: []*models.DeadLetter{poison, failing}}
+
+	bus := memory.NewMemoryBus(10)
+	defer bus.Close()
+	published := make(chan string, 10)
+	if err := bus.Subscribe("messages/pb", func(msg interfaces.BusMessage) {
+		published <- string(msg.Payload())
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{
+		bus: bus,
+		appCtx: appcontext.AppContext{
+			Config: config.AppConfig{
+				DeadLetterSink:       models.DeadLetterSinkMongo,
+				DeadLetterMaxReplays: 2,
+			},
+			Log:             logger.NewLogger(),
+			DeadLetterStore: store,
+		},
+	}
+	c.config = &c.appCtx.Config
+
+	// The older letter replayed too often does not take the only place.
+	replayed, err := c.replayFromStore(1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if replayed != 1 {
+		t.Fatalf("Expected only the message below the replay limit to be replayed, got %d", replayed)
+	}
+	select {
+	case payload := <-published:
+		if payload != "failing" {
+			t.Errorf("Expected the failing message to be replayed, got %q", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the replayed message")
+	}
+
+	// Failing again, the replayed message is listed with the attempts of both failures.
+	again := models.NewDeadLetter("messages/pb", []byte("failing"), models.DeadLetterStageStore, 3, errors.New("down"))
+	_ = store.Add(again)
+	letters, _ := store.List(0, 0)
+	if len(letters) != 2 {
+		t.Fatalf("Expected both letters to be kept, got %d", len(letters))
+	}
+	for _, letter := range letters {
+		if letter.ID == failing.ID && (letter.Attempts != 6 || letter.Replays != 1) {
+			t.Errorf("Expected 6 attempts over 1 replay, got %+v", letter)
+		}
+	}
+}
+
+func TestDeadLetter_FailedAgain(t *testing.T) {
+	earlier := &models.DeadLetter{Attempts: 3, Replays: 1}
+	letter := &models.DeadLetter{Attempts: 3}
+	letter.FailedAgain(earlier)
+	if letter.Attempts != 6 || letter.Replays != 2 {
+		t.Errorf("Expected 6 attempts over 2 replays, got %+v", letter)
+	}
+}
+
+func TestValidDeadLetterSink(t *testing.T) {
+	for _, cfg := range []config.AppConfig{
+		{DeadLetterSink: "kafka"},
+		{DeadLetterSink: models.DeadLetterSinkMQTT},
+	} {
+		if err := validDeadLetterSink(&cfg); err == nil {
+			t.Errorf("Expected an error for %+v", cfg)
+		}
+	}
+
+	for _, cfg := range []config.AppConfig{
+		{},
+		{DeadLetterSink: models.DeadLetterSinkMongo},
+		{DeadLetterSink: models.DeadLetterSinkMQTT, DeadLetterTopic: "dead-letters"},
+	} {
+		if err := validDeadLetterSink(&cfg); err != nil {
+			t.Errorf("Unexpected error for %+v: %v", cfg, err)
+		}
+	}
+}
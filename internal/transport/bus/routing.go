@@ -0,0 +1,109 @@
+package bus
+
+import (
+	"strings"
+	"text/template"
+
+	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/templates"
+)
+
+// RouteFields are the values a routing template can use. A message is routed once
+// for every category and ticker it has.
+type RouteFields struct {
+	Category  string
+	Sentiment string
+	Language  string
+	Ticker    string
+}
+
+// TopicRouter derives the topics a classified message is republished to, e.g.
+// "blueskyfh/classified/{{.Category}}/{{.Sentiment}}".
+type TopicRouter struct {
+	templates []*template.Template
+}
+
+// NewTopicRouter parses the routing templates.
+func NewTopicRouter(sources []string) (*TopicRouter, error) {
+	router := &TopicRouter{}
+
+	for _, source := range sources {
+		tmpl, err := templates.Parse("route", source, RouteFields{})
+		if err != nil {
+			return nil, err
+		}
+
+		router.templates = append(router.templates, tmpl)
+	}
+	return router, nil
+}
+
+// Topics returns the distinct topics msg is routed to. Topics with an empty level,
+// such as a ticker topic for a message without tickers, are skipped.
+func (tr *TopicRouter) Topics(msg *models.ProtoMessage) ([]string, error) {
+	fields := RouteFields{
+		Sentiment: topicLevel(msg.GetFinSentiment()),
+	}
+	if langs := msg.GetCommit().GetRecord().GetLangs(); len(langs) > 0 {
+		fields.Language = topicLevel(langs[0])
+	}
+
+	categories := orEmpty(msg.GetCategories())
+	tickers := orEmpty(msg.GetTickers())
+
+	var topics []string
+	seen := make(map[string]bool)
+	for _, tmpl := range tr.templates {
+		for _, category := range categories {
+			for _, ticker := range tickers {
+				fields.Category = topicLevel(category)
+				fields.Ticker = topicLevel(ticker)
+
+				var sb strings.Builder
+				if err := tmpl.Execute(&sb, fields); err != nil {
+					return nil, err
+				}
+
+				topic := sb.String()
+				if !validRoutedTopic(topic) || seen[topic] {
+					continue
+				}
+				seen[topic] = true
+				topics = append(topics, topic)
+			}
+		}
+	}
+	return topics, nil
+}
+
+// orEmpty returns values, or a single empty value so templates not using the list
+// are still rendered.
+func orEmpty(values []string) []string {
+	if len(values) == 0 {
+		return []string{""}
+	}
+	return values
+}
+
+// topicLevel makes value usable as a single topic level.
+func topicLevel(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '+', '#', ' ':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(value))
+}
+
+func validRoutedTopic(topic string) bool {
+	if topic == "" || strings.HasPrefix(topic, "$") {
+		return false
+	}
+	for _, level := range strings.Split(topic, "/") {
+		if level == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package bus
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTopicRouter_Topics(t *testing.T) {
+	msg := sampleMessage()
+
+	testCases := []struct {
+		name      string
+		templates []string
+		expected  []string
+	}{
+		{
+			name:      "Category and sentiment",
+			templates: []string{"blueskyfh/classified/{{.Category}}/{{.Sentiment}}"},
+			expected: []string{
+				"blueskyfh/classified/economy/negative",
+				"blueskyfh/classified/business/negative",
+				"blueskyfh/classified/finance/negative",
+			},
+		},
+		{
+			name: "Tickers and language",
+			templates: []string{
+				"blueskyfh/tickers/{{.Ticker}}",
+				"blueskyfh/lang/{{.Language}}",
+			},
+			expected: []string{
+				"blueskyfh/tickers/SPY",
+				"blueskyfh/tickers/QQQ",
+				"blueskyfh/lang/en",
+			},
+		},
+		{
+			name:      "Conditional level",
+			templates: []string{`blueskyfh/{{if eq .Sentiment "negative"}}alerts{{end}}/{{.Category}}`},
+			expected: []string{
+				"blueskyfh/alerts/economy",
+				"blueskyfh/alerts/business",
+				"blueskyfh/alerts/finance",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, err := NewTopicRouter(tc.templates)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			topics, err := router.Topics(msg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !slices.Equal(topics, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, topics)
+			}
+		})
+	}
+}
+
+func TestTopicRouter_SkipsEmptyLevels(t *testing.T) {
+	msg := sampleMessage()
+	msg.Tickers = nil
+	msg.Categories = []string{"science/tech"}
+
+	router, err := NewTopicRouter([]string{
+		"blueskyfh/tickers/{{.Ticker}}",
+		"blueskyfh/categories/{{.Category}}",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	topics, err := router.Topics(msg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(topics, []string{"blueskyfh/categories/science_tech"}) {
+		t.Errorf("Expected only the sanitized category topic, got %v", topics)
+	}
+}
+
+func TestNewTopicRouter_InvalidTemplate(t *testing.T) {
+	for _, source := range []string{"blueskyfh/{{.Category", "blueskyfh/{{.Author}}"} {
+		if _, err := NewTopicRouter([]string{source}); err == nil {
+			t.Errorf("Expected an error for %q", source)
+		}
+	}
+}
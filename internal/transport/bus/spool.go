@@ -0,0 +1,437 @@
+package bus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/domain"
+)
+
+// A spooled record is a header followed by the topic and the payload. The checksum
+// covers everything after it, so a record torn by a crash is detected on open.
+//
+//	crc32 (4) | spooled at, unix nanoseconds (8) | topic length (4) | payload length (4)
+const spoolHeaderSize = 20
+
+const spoolSegmentExt = ".spool"
+
+var errSpoolClosed = errors.New("spool is closed")
+
+// spoolRecord is a message waiting in the spool.
+type spoolRecord struct {
+	Topic     string
+	Data      []byte
+	SpooledAt time.Time
+	size      int64
+}
+
+type spoolSegment struct {
+	seq      uint64
+	path     string
+	size     int64
+	messages int // not yet read
+}
+
+// Spool buffers messages on disk in segment files while the broker is unreachable
+// and hands them back in the order they were appended. Segments are deleted once
+// read, and the oldest ones are dropped when the spool grows over its maximum size.
+type Spool struct {
+	dir         string
+	segmentSize int64
+	maxSize     int64
+
+	mu         sync.Mutex
+	segments   []*spoolSegment // oldest first, the last one is written to
+	writer     *os.File
+	reader     *os.File // reads the oldest segment
+	readOffset int64
+	peeked     *spoolRecord
+	size       int64
+	dropped    int64
+	closed     bool
+}
+
+// OpenSpool opens the spool in dir, creating it when needed, and recovers the
+// messages left by a previous run.
+func OpenSpool(dir string, segmentSize, maxSize int64) (*Spool, error) {
+	if segmentSize <= 0 || maxSize < segmentSize {
+		return nil, fmt.Errorf(
+			"invalid spool sizes: segment %d bytes, maximum %d bytes",
+			segmentSize,
+			maxSize,
+		)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating spool directory %s: %w", dir, err)
+	}
+
+	s := &Spool{dir: dir, segmentSize: segmentSize, maxSize: maxSize}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading spool directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		var seq uint64
+		name := entry.Name()
+		if !strings.HasSuffix(name, spoolSegmentExt) {
+			continue
+		}
+		if _, err := fmt.Sscanf(strings.TrimSuffix(name, spoolSegmentExt), "%d", &seq); err != nil {
+			continue
+		}
+
+		segment := &spoolSegment{seq: seq, path: filepath.Join(dir, name)}
+		if err := recoverSegment(segment); err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, segment)
+		s.size += segment.size
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+
+	return s, nil
+}
+
+// recoverSegment counts the records of a segment and truncates a record torn by a
+// crash while it was written.
+func recoverSegment(segment *spoolSegment) error {
+	f, err := os.OpenFile(segment.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("opening spool segment %s: %w", segment.path, err)
+	}
+	defer f.Close()
+
+	var offset int64
+	for {
+		record, err := readSpoolRecord(f)
+		if err != nil {
+			break
+		}
+		offset += record.size
+		segment.messages++
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		return fmt.Errorf("truncating spool segment %s: %w", segment.path, err)
+	}
+	segment.size = offset
+	return nil
+}
+
+func readSpoolRecord(r io.Reader) (*spoolRecord, error) {
+	header := make([]byte, spoolHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	topicLen := binary.BigEndian.Uint32(header[12:16])
+	dataLen := binary.BigEndian.Uint32(header[16:20])
+
+	body := make([]byte, int(topicLen)+int(dataLen))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(body)
+	if crc.Sum32() != binary.BigEndian.Uint32(header[0:4]) {
+		return nil, errors.New("spool record checksum mismatch")
+	}
+
+	return &spoolRecord{
+		Topic:     string(body[:topicLen]),
+		Data:      body[topicLen:],
+		SpooledAt: time.Unix(0, int64(binary.BigEndian.Uint64(header[4:12]))),
+		size:      int64(spoolHeaderSize + len(body)),
+	}, nil
+}
+
+func encodeSpoolRecord(topic string, data []byte, spooledAt time.Time) []byte {
+	buf := make([]byte, spoolHeaderSize+len(topic)+len(data))
+	binary.BigEndian.PutUint64(buf[4:12], uint64(spooledAt.UnixNano()))
+	binary.BigEndian.PutUint32(buf[12:16], uint32(len(topic)))
+	binary.BigEndian.PutUint32(buf[16:20], uint32(len(data)))
+	copy(buf[spoolHeaderSize:], topic)
+	copy(buf[spoolHeaderSize+len(topic):], data)
+	binary.BigEndian.PutUint32(buf[0:4], crc32.ChecksumIEEE(buf[4:]))
+	return buf
+}
+
+// Append adds a message at the end of the spool.
+func (s *Spool) Append(topic string, data []byte) error {
+	record := encodeSpoolRecord(topic, data, time.Now())
+	size := int64(len(record))
+	if size > s.segmentSize {
+		return fmt.Errorf("message of %d bytes exceeds the spool segment size", size)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errSpoolClosed
+	}
+
+	for s.size+size > s.maxSize && len(s.segments) > 1 {
+		if err := s.dropOldest(); err != nil {
+			return err
+		}
+	}
+
+	if s.writer == nil || s.segments[len(s.segments)-1].size+size > s.segmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.writer.Write(record); err != nil {
+		return fmt.Errorf("writing to spool: %w", err)
+	}
+	segment := s.segments[len(s.segments)-1]
+	segment.size += size
+	segment.messages++
+	s.size += size
+	return nil
+}
+
+// rotate starts a new segment to write to.
+func (s *Spool) rotate() error {
+	var seq uint64
+	if len(s.segments) > 0 {
+		seq = s.segments[len(s.segments)-1].seq + 1
+	}
+	path := filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolSegmentExt))
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("creating spool segment %s: %w", path, err)
+	}
+	if s.writer != nil {
+		if err := s.writer.Close(); err != nil {
+			return fmt.Errorf("closing spool segment: %w", err)
+		}
+	}
+
+	s.writer = f
+	s.segments = append(s.segments, &spoolSegment{seq: seq, path: path})
+	return nil
+}
+
+// dropOldest deletes the oldest segment with the messages it still holds.
+func (s *Spool) dropOldest() error {
+	s.dropped += int64(s.segments[0].messages)
+	return s.removeOldest()
+}
+
+func (s *Spool) removeOldest() error {
+	oldest := s.segments[0]
+	if s.reader != nil {
+		if err := s.reader.Close(); err != nil {
+			return fmt.Errorf("closing spool segment: %w", err)
+		}
+		s.reader = nil
+	}
+	if err := os.Remove(oldest.path); err != nil {
+		return fmt.Errorf("removing spool segment %s: %w", oldest.path, err)
+	}
+
+	s.segments = s.segments[1:]
+	s.size -= oldest.size
+	s.readOffset = 0
+	s.peeked = nil
+	return nil
+}
+
+// Peek returns the oldest message without removing it, ok is false when the spool
+// is empty.
+func (s *Spool) Peek() (topic string, data []byte, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return "", nil, false, errSpoolClosed
+	}
+
+	if s.peeked == nil {
+		record, err := s.next()
+		if record == nil || err != nil {
+			return "", nil, false, err
+		}
+		s.peeked = record
+	}
+	return s.peeked.Topic, s.peeked.Data, true, nil
+}
+
+// next reads the record at the read offset, skipping fully read segments.
+func (s *Spool) next() (*spoolRecord, error) {
+	for len(s.segments) > 0 {
+		oldest := s.segments[0]
+		if oldest.messages > 0 {
+			break
+		}
+		// The segment being written is kept, even when read up to its end.
+		if len(s.segments) == 1 {
+			return nil, nil
+		}
+		if err := s.removeOldest(); err != nil {
+			return nil, err
+		}
+	}
+	if len(s.segments) == 0 {
+		return nil, nil
+	}
+
+	if s.reader == nil {
+		f, err := os.Open(s.segments[0].path)
+		if err != nil {
+			return nil, fmt.Errorf("opening spool segment: %w", err)
+		}
+		if _, err := f.Seek(s.readOffset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("seeking spool segment: %w", err)
+		}
+		s.reader = f
+	}
+
+	record, err := readSpoolRecord(s.reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading spool segment %s: %w", s.segments[0].path, err)
+	}
+	return record, nil
+}
+
+// Remove removes the message returned by the last Peek.
+func (s *Spool) Remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.peeked == nil {
+		// Dropped with its segment since it was peeked.
+		return nil
+	}
+	s.readOffset += s.peeked.size
+	s.segments[0].messages--
+	s.peeked = nil
+
+	// Read segments are deleted straight away. The one still written to is emptied
+	// instead, so its messages are not read again after a restart.
+	if s.segments[0].messages > 0 {
+		return nil
+	}
+	if len(s.segments) > 1 {
+		return s.removeOldest()
+	}
+	return s.truncateLast()
+}
+
+// truncateLast empties the last segment once all its messages were read.
+func (s *Spool) truncateLast() error {
+	last := s.segments[0]
+	if s.reader != nil {
+		if err := s.reader.Close(); err != nil {
+			return fmt.Errorf("closing spool segment: %w", err)
+		}
+		s.reader = nil
+	}
+	// The writer appends, so it continues at the start of the emptied file.
+	if err := os.Truncate(last.path, 0); err != nil {
+		return fmt.Errorf("truncating spool segment %s: %w", last.path, err)
+	}
+
+	s.size -= last.size
+	last.size = 0
+	s.readOffset = 0
+	return nil
+}
+
+// Len returns the number of spooled messages.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.messages()
+}
+
+func (s *Spool) messages() int {
+	n := 0
+	for _, segment := range s.segments {
+		n += segment.messages
+	}
+	return n
+}
+
+// Stats returns the size of the spool and the age of its oldest message.
+func (s *Spool) Stats() domain.SpoolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := domain.SpoolStats{
+		Messages: s.messages(),
+		Bytes:    s.size,
+		Segments: len(s.segments),
+		Dropped:  s.dropped,
+	}
+	if stats.Messages > 0 && !s.closed {
+		if spooledAt, ok := s.oldestSpooledAt(); ok {
+			stats.OldestAge = time.Since(spooledAt)
+		}
+	}
+	return stats
+}
+
+// oldestSpooledAt returns when the oldest message was spooled. It reads the record's
+// header on its own, the read cursor belongs to the drain.
+func (s *Spool) oldestSpooledAt() (time.Time, bool) {
+	if s.peeked != nil {
+		return s.peeked.SpooledAt, true
+	}
+
+	for i, segment := range s.segments {
+		if segment.messages == 0 {
+			continue
+		}
+		var offset int64
+		if i == 0 {
+			offset = s.readOffset
+		}
+
+		f, err := os.Open(segment.path)
+		if err != nil {
+			return time.Time{}, false
+		}
+		defer f.Close()
+
+		header := make([]byte, spoolHeaderSize)
+		if _, err := f.ReadAt(header, offset); err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(0, int64(binary.BigEndian.Uint64(header[4:12]))), true
+	}
+	return time.Time{}, false
+}
+
+// Close closes the segment files, spooled messages are kept for the next run.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, f := range []*os.File{s.writer, s.reader} {
+		if f != nil {
+			errs = append(errs, f.Close())
+		}
+	}
+	s.writer, s.reader, s.peeked = nil, nil, nil
+	s.closed = true
+	return errors.Join(errs...)
+}
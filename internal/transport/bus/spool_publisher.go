@@ -0,0 +1,129 @@
+package bus
+
+import (
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/domain"
+)
+
+// spoolRetryInterval is how often draining the spool is retried while messages wait.
+var spoolRetryInterval = 5 * time.Second
+
+// EnableSpool buffers the messages published by PublishMessage in BUS_SPOOL_DIR
+// while the broker is unreachable. They are published in order once the client is
+// connected again, before newer messages.
+func (c *Client) EnableSpool() error {
+	const mb = 1 << 20
+
+	spool, err := OpenSpool(
+		c.config.BusSpoolDir,
+		int64(c.config.BusSpoolSegmentMB)*mb,
+		int64(c.config.BusSpoolMaxMB)*mb,
+	)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.spool = spool
+	c.mu.Unlock()
+
+	if n := spool.Len(); n > 0 {
+		c.appCtx.Log.Info("Recovered %d spooled messages from %s", n, c.config.BusSpoolDir)
+	}
+
+	go c.runSpoolDrain()
+	c.signalDrain()
+	return nil
+}
+
+// SpoolStats returns the size of the spool, empty stats when it is not enabled.
+func (c *Client) SpoolStats() domain.SpoolStats {
+	if spool := c.currentSpool(); spool != nil {
+		return spool.Stats()
+	}
+	return domain.SpoolStats{}
+}
+
+func (c *Client) currentSpool() *Spool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.spool
+}
+
+// spoolOrPublish publishes data, or spools it while the client is disconnected or
+// older messages are still spooled, so messages keep their order.
+func (c *Client) spoolOrPublish(topic string, data []byte) error {
+	spool := c.currentSpool()
+	if spool == nil {
+		return c.publish(topic, data)
+	}
+
+	if spool.Len() == 0 && c.IsConnected() {
+		err := c.publish(topic, data)
+		if err == nil {
+			return nil
+		}
+		c.appCtx.Log.Error("Failed to publish, spooling message", err)
+	}
+
+	if err := spool.Append(topic, data); err != nil {
+		return err
+	}
+	c.signalDrain()
+	return nil
+}
+
+func (c *Client) signalDrain() {
+	select {
+	case c.drainCh <- struct{}{}:
+	default:
+	}
+}
+
+// runSpoolDrain drains the spool whenever messages are spooled and periodically, to
+// publish them once the broker is reachable again.
+func (c *Client) runSpoolDrain() {
+	ticker := time.NewTicker(spoolRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.drainCh:
+		case <-ticker.C:
+		case <-c.stopped:
+			return
+		}
+		c.drainSpool()
+	}
+}
+
+func (c *Client) drainSpool() {
+	spool := c.currentSpool()
+
+	drained := 0
+	for c.IsConnected() {
+		topic, data, ok, err := spool.Peek()
+		if err != nil {
+			c.appCtx.Log.Error("Failed to read spooled message", err)
+			return
+		}
+		if !ok {
+			break
+		}
+
+		if err := c.publish(topic, data); err != nil {
+			c.appCtx.Log.Error("Failed to publish spooled message", err)
+			return
+		}
+		if err := spool.Remove(); err != nil {
+			c.appCtx.Log.Error("Failed to remove spooled message", err)
+			return
+		}
+		drained++
+	}
+
+	if drained > 0 {
+		c.appCtx.Log.Info("Published %d spooled messages, %d left", drained, spool.Len())
+	}
+}
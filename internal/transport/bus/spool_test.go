@@ -0,0 +1,190 @@
+package bus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func drain(t *testing.T, spool *Spool) []string {
+	t.Helper()
+
+	var payloads []string
+	for {
+		topic, data, ok, err := spool.Peek()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !ok {
+			return payloads
+		}
+		payloads = append(payloads, topic+":"+string(data))
+		if err := spool.Remove(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func appendN(t *testing.T, spool *Spool, from, to int) {
+	t.Helper()
+
+	for i := from; i < to; i++ {
+		if err := spool.Append("messages", []byte(fmt.Sprintf("post-%02d", i))); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestSpool_OrderAcrossSegmentsAndRestarts(t *testing.T) {
+	dir := t.TempDir()
+
+	// Each record is 20 + 8 + 7 bytes, so a segment holds two of them.
+	spool, err := OpenSpool(dir, 80, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	appendN(t, spool, 0, 5)
+
+	stats := spool.Stats()
+	if stats.Messages != 5 || stats.Segments != 3 || stats.Bytes != 5*35 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Read one message and restart, the rest is recovered from disk.
+	if _, data, _, _ := spool.Peek(); string(data) != "post-00" {
+		t.Fatalf("Expected the oldest message first, got %q", data)
+	}
+	if err := spool.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	spool, err = OpenSpool(dir, 80, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer spool.Close()
+	appendN(t, spool, 5, 7)
+
+	// The first segment was not fully read, so post-00 is published again.
+	payloads := drain(t, spool)
+	expected := []string{"post-00", "post-01", "post-02", "post-03", "post-04", "post-05", "post-06"}
+	if len(payloads) != len(expected) {
+		t.Fatalf("Expected %d messages, got %v", len(expected), payloads)
+	}
+	for i, payload := range payloads {
+		if payload != "messages:"+expected[i] {
+			t.Errorf("Expected %s at %d, got %s", expected[i], i, payload)
+		}
+	}
+
+	if stats := spool.Stats(); stats.Messages != 0 || stats.Segments != 1 {
+		t.Errorf("Expected read segments to be removed, got %+v", stats)
+	}
+}
+
+func TestSpool_DropsOldestOverMaxSize(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir(), 80, 160)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer spool.Close()
+
+	appendN(t, spool, 0, 6)
+
+	stats := spool.Stats()
+	if stats.Dropped != 2 || stats.Messages != 4 || stats.Bytes > 160 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if payloads := drain(t, spool); payloads[0] != "messages:post-02" {
+		t.Errorf("Expected the oldest segment to be dropped, got %v", payloads)
+	}
+}
+
+func TestSpool_TruncatesTornRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	spool, err := OpenSpool(dir, 1000, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	appendN(t, spool, 0, 2)
+	if err := spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of writing a third record.
+	path := filepath.Join(dir, fmt.Sprintf("%020d%s", 0, spoolSegmentExt))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(encodeSpoolRecord("messages", []byte("post-02"), time.Now())[:30]); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	spool, err = OpenSpool(dir, 1000, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer spool.Close()
+	appendN(t, spool, 3, 4)
+
+	payloads := drain(t, spool)
+	if len(payloads) != 3 || payloads[2] != "messages:post-03" {
+		t.Errorf("Expected the torn record to be discarded, got %v", payloads)
+	}
+}
+
+func TestSpool_DrainedSpoolIsEmptyAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	spool, err := OpenSpool(dir, 80, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	appendN(t, spool, 0, 5)
+	drain(t, spool)
+	if err := spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	spool, err = OpenSpool(dir, 80, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer spool.Close()
+	if n := spool.Len(); n != 0 {
+		t.Fatalf("Expected the drained messages not to be recovered, got %d", n)
+	}
+
+	// The emptied segment is written to again.
+	appendN(t, spool, 5, 6)
+	if payloads := drain(t, spool); len(payloads) != 1 || payloads[0] != "messages:post-05" {
+		t.Errorf("Expected only the new message, got %v", payloads)
+	}
+}
+
+func TestSpool_StatsKeepsTheReadCursor(t *testing.T) {
+	spool, err := OpenSpool(t.TempDir(), 80, 160)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer spool.Close()
+
+	appendN(t, spool, 0, 2)
+	if stats := spool.Stats(); stats.OldestAge <= 0 {
+		t.Errorf("Expected the age of the oldest message, got %+v", stats)
+	}
+
+	// Dropping the oldest segment after Stats must not skip the message read next.
+	appendN(t, spool, 2, 6)
+	if payloads := drain(t, spool); len(payloads) != 4 || payloads[0] != "messages:post-02" {
+		t.Errorf("Expected the messages of the kept segments, got %v", payloads)
+	}
+}
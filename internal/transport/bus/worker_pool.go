@@ -0,0 +1,128 @@
+package bus
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/domain"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+)
+
+// workerPool processes received messages on a fixed number of goroutines. Messages
+// wait in a bounded queue; when it is full the bus's delivery blocks, which stops
+// the broker from sending more than its in-flight window of unacknowledged messages.
+type workerPool struct {
+	queue   chan interfaces.BusMessage
+	closing chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	workers int
+
+	busy      atomic.Int64
+	processed atomic.Int64
+	full      atomic.Int64
+	waitTime  atomic.Int64
+}
+
+func newWorkerPool(workers, queueSize int, handle func(interfaces.BusMessage)) *workerPool {
+	p := &workerPool{
+		queue:   make(chan interfaces.BusMessage, queueSize),
+		closing: make(chan struct{}),
+		workers: workers,
+	}
+
+	p.wg.Add(workers)
+	for range workers {
+		go p.run(handle)
+	}
+	return p
+}
+
+func (p *workerPool) run(handle func(interfaces.BusMessage)) {
+	defer p.wg.Done()
+
+	process := func(msg interfaces.BusMessage) {
+		p.busy.Add(1)
+		handle(msg)
+		p.busy.Add(-1)
+		p.processed.Add(1)
+	}
+
+	for {
+		select {
+		case msg := <-p.queue:
+			process(msg)
+		case <-p.closing:
+			// Drain what was queued before closing.
+			for {
+				select {
+				case msg := <-p.queue:
+					process(msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Submit queues msg, waiting for room while the queue is full. It reports false
+// when the pool is closing and msg was not queued.
+func (p *workerPool) Submit(msg interfaces.BusMessage) bool {
+	select {
+	case <-p.closing:
+		return false
+	default:
+	}
+
+	select {
+	case p.queue <- msg:
+		return true
+	default:
+	}
+
+	p.full.Add(1)
+	start := time.Now()
+	defer func() { p.waitTime.Add(int64(time.Since(start))) }()
+
+	select {
+	case p.queue <- msg:
+		return true
+	case <-p.closing:
+		return false
+	}
+}
+
+// Close stops accepting messages and waits up to timeout for the queued ones to be
+// processed.
+func (p *workerPool) Close(timeout time.Duration) error {
+	p.once.Do(func() { close(p.closing) })
+
+	drained := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out draining %d queued messages", len(p.queue)+int(p.busy.Load()))
+	}
+}
+
+// Stats returns the current queue counters.
+func (p *workerPool) Stats() domain.QueueStats {
+	return domain.QueueStats{
+		Length:    len(p.queue),
+		Capacity:  cap(p.queue),
+		Busy:      int(p.busy.Load()),
+		Workers:   p.workers,
+		Processed: p.processed.Load(),
+		Full:      p.full.Load(),
+		WaitTime:  time.Duration(p.waitTime.Load()),
+	}
+}
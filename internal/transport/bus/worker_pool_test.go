@@ -0,0 +1,86 @@
+package bus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+)
+
+func TestWorkerPool_DrainsOnClose(t *testing.T) {
+	var handled, running, maxRunning atomic.Int64
+	release := make(chan struct{})
+
+	pool := newWorkerPool(2, 3, func(msg interfaces.BusMessage) {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		handled.Add(1)
+	})
+
+	// Two messages keep both workers busy and three fill the queue.
+	submit := func(n int) {
+		for range n {
+			if !pool.Submit(&fakeMessage{topic: "messages"}) {
+				t.Fatal("Expected the message to be queued")
+			}
+		}
+	}
+	submit(2)
+	for running.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	submit(3)
+
+	blocked := make(chan bool)
+	go func() { blocked <- pool.Submit(&fakeMessage{topic: "messages"}) }()
+
+	select {
+	case <-blocked:
+		t.Fatal("Expected Submit to wait while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if !<-blocked {
+		t.Fatal("Expected the waiting message to be queued once there was room")
+	}
+
+	if err := pool.Close(time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if handled.Load() != 6 {
+		t.Errorf("Expected every queued message to be handled, got %d", handled.Load())
+	}
+	if maxRunning.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent handlers, got %d", maxRunning.Load())
+	}
+
+	stats := pool.Stats()
+	if stats.Processed != 6 || stats.Full != 1 || stats.Capacity != 3 || stats.Workers != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	if pool.Submit(&fakeMessage{topic: "messages"}) {
+		t.Error("Expected a closed pool to refuse messages")
+	}
+}
+
+func TestWorkerPool_CloseTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	pool := newWorkerPool(1, 1, func(msg interfaces.BusMessage) { <-release })
+	pool.Submit(&fakeMessage{topic: "messages"})
+
+	if err := pool.Close(10 * time.Millisecond); err == nil {
+		t.Error("Expected an error when the queue is not drained in time")
+	}
+}
@@ -0,0 +1,310 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+)
+
+// requestTimeout bounds the wait for the brokers to acknowledge a publish.
+const requestTimeout = 10 * time.Second
+
+// fetchRetryInterval is the pause after a failed fetch before fetching again.
+var fetchRetryInterval = time.Second
+
+// writer writes messages to the brokers, a *kafka.Writer.
+type writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// reader reads a topic as a member of a consumer group, a *kafka.Reader.
+type reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaBus is the Kafka backend of the message bus. Subscribers join the consumer
+// group KAFKA_GROUP, which shares the partitions of a topic between them.
+type KafkaBus struct {
+	appCtx    appcontext.AppContext
+	writer    writer
+	newReader func(topic string) reader
+
+	mu            sync.Mutex
+	closed        bool
+	subscriptions map[string]*subscription
+}
+
+// Ensure KafkaBus implements the interfaces.Bus interface.
+var _ interfaces.Bus = (*KafkaBus)(nil)
+
+// NewKafkaBus creates a KafkaBus for KAFKA_BROKERS identifying itself as clientID.
+// Connections are made when messages are published or a topic is subscribed to.
+func NewKafkaBus(appCtx appcontext.AppContext, clientID string) (*KafkaBus, error) {
+	cfg := &appCtx.Config
+	if len(cfg.KafkaBrokers) == 0 {
+		return nil, errors.New("KAFKA_BROKERS is required with the kafka message bus")
+	}
+
+	w := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.KafkaBrokers...),
+		Balancer:               &kafka.LeastBytes{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: cfg.KafkaAutoCreate,
+		// Publishes wait for their batch to be written, a short timeout keeps a
+		// lone message from waiting for a full batch.
+		BatchTimeout: 10 * time.Millisecond,
+		Transport:    &kafka.Transport{ClientID: clientID},
+	}
+
+	newReader := func(topic string) reader {
+		return kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        cfg.KafkaBrokers,
+			GroupID:        cfg.KafkaGroup,
+			Topic:          topic,
+			StartOffset:    kafka.FirstOffset,
+			CommitInterval: time.Second,
+			Dialer:         &kafka.Dialer{ClientID: clientID, Timeout: requestTimeout},
+		})
+	}
+
+	appCtx.Log.Info("Kafka brokers: %s", strings.Join(cfg.KafkaBrokers, ","))
+	appCtx.Log.Info("Client ID: %s, Consumer Group: %s", clientID, cfg.KafkaGroup)
+	return newKafkaBus(appCtx, w, newReader), nil
+}
+
+func newKafkaBus(appCtx appcontext.AppContext, w writer, newReader func(string) reader) *KafkaBus {
+	return &KafkaBus{
+		appCtx:        appCtx,
+		writer:        w,
+		newReader:     newReader,
+		subscriptions: make(map[string]*subscription),
+	}
+}
+
+// Topic maps a topic to a Kafka topic name. The "/" separated levels become "."
+// separated and characters Kafka does not allow are replaced with "_".
+func Topic(topic string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '/':
+			return '.'
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, topic)
+}
+
+// IsConnected reports whether the bus is open. Kafka clients connect to the broker
+// owning a partition when they need it, so there is no single connection to check.
+func (b *KafkaBus) IsConnected() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.closed
+}
+
+// Publish writes data to topic and waits for all in-sync replicas to store it.
+func (b *KafkaBus) Publish(topic string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	name := Topic(topic)
+	if err := b.writer.WriteMessages(ctx, kafka.Message{Topic: name, Value: data}); err != nil {
+		return fmt.Errorf("error publishing to Kafka topic %s: %w", name, err)
+	}
+	return nil
+}
+
+// Subscribe reads topic and hands its messages to handler one at a time, so a
+// handler waiting for room in its queue stops the reads.
+func (b *KafkaBus) Subscribe(topic string, handler func(interfaces.BusMessage)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return errors.New("kafka bus is closed")
+	}
+	if previous, ok := b.subscriptions[topic]; ok {
+		previous.stop()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{
+		topic:   topic,
+		reader:  b.newReader(Topic(topic)),
+		offsets: newOffsetTracker(),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		appCtx:  b.appCtx,
+	}
+	b.subscriptions[topic] = sub
+	go sub.run(ctx, handler)
+
+	b.appCtx.Log.Info("Successfully subscribed to Kafka topic: %s", Topic(topic))
+	return nil
+}
+
+// Unsubscribe stops reading topic, committing the offsets of the acknowledged messages.
+func (b *KafkaBus) Unsubscribe(topic string) error {
+	b.mu.Lock()
+	sub, ok := b.subscriptions[topic]
+	delete(b.subscriptions, topic)
+	b.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return sub.stop()
+}
+
+// Close stops the subscriptions and flushes the writer.
+func (b *KafkaBus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	subs := b.subscriptions
+	b.subscriptions = make(map[string]*subscription)
+	b.mu.Unlock()
+
+	var errs []error
+	for _, sub := range subs {
+		errs = append(errs, sub.stop())
+	}
+	errs = append(errs, b.writer.Close())
+	return errors.Join(errs...)
+}
+
+// subscription reads a topic until stopped.
+type subscription struct {
+	topic   string
+	reader  reader
+	offsets *offsetTracker
+	cancel  context.CancelFunc
+	done    chan struct{}
+	appCtx  appcontext.AppContext
+	once    sync.Once
+}
+
+func (s *subscription) run(ctx context.Context, handler func(interfaces.BusMessage)) {
+	defer close(s.done)
+
+	for {
+		msg, err := s.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.appCtx.Log.Error("Failed to read Kafka topic %s", err, Topic(s.topic))
+			select {
+			case <-time.After(fetchRetryInterval):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		s.offsets.Fetched(msg)
+		handler(&message{topic: s.topic, msg: msg, sub: s})
+	}
+}
+
+// commit commits the offsets up to msg once every earlier message of its partition
+// is acknowledged too, so a restart does not skip messages still being processed.
+func (s *subscription) commit(msg kafka.Message) {
+	committable, ok := s.offsets.Acked(msg)
+	if !ok {
+		return
+	}
+	if err := s.reader.CommitMessages(context.Background(), committable); err != nil {
+		s.appCtx.Log.Error("Failed to commit Kafka offset of %s", err, committable.Topic)
+	}
+}
+
+func (s *subscription) stop() error {
+	var err error
+	s.once.Do(func() {
+		s.cancel()
+		<-s.done
+		err = s.reader.Close()
+	})
+	return err
+}
+
+// message is a message read from a Kafka topic, acknowledging it commits its offset.
+type message struct {
+	topic string
+	msg   kafka.Message
+	sub   *subscription
+}
+
+func (m *message) Topic() string   { return m.topic }
+func (m *message) Payload() []byte { return m.msg.Value }
+func (m *message) Ack()            { m.sub.commit(m.msg) }
+
+// offsetTracker keeps the offsets fetched from each partition that are not committed
+// yet. Messages are acknowledged out of order by the workers processing them, but a
+// partition's offset can only move past messages that are all acknowledged.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	pending []int64 // fetched and not committed, in fetch order
+	acked   map[int64]bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[int]*partitionOffsets)}
+}
+
+// Fetched records a message handed to the handler.
+func (t *offsetTracker) Fetched(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		p = &partitionOffsets{acked: make(map[int64]bool)}
+		t.partitions[msg.Partition] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// Acked records the acknowledgement of msg and returns the last message of its
+// partition whose offset can be committed, if any.
+func (t *offsetTracker) Acked(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	p.acked[msg.Offset] = true
+
+	committed := int64(-1)
+	for len(p.pending) > 0 && p.acked[p.pending[0]] {
+		committed = p.pending[0]
+		delete(p.acked, committed)
+		p.pending = p.pending[1:]
+	}
+	if committed < 0 {
+		return kafka.Message{}, false
+	}
+	return kafka.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: committed}, true
+}
@@ -0,0 +1,194 @@
+package kafka
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	kafka "github.com/segmentio/kafka-go"
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+	"stockseer.ai/blueksy-firehose/internal/logger"
+)
+
+// memoryBroker stands in for the Kafka brokers, topics are single partition logs.
+type memoryBroker struct {
+	mu        sync.Mutex
+	logs      map[string][]kafka.Message
+	committed map[string]int64 // next offset to read per topic
+}
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{
+		logs:      make(map[string][]kafka.Message),
+		committed: make(map[string]int64),
+	}
+}
+
+func (b *memoryBroker) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, msg := range msgs {
+		msg.Offset = int64(len(b.logs[msg.Topic]))
+		b.logs[msg.Topic] = append(b.logs[msg.Topic], msg)
+	}
+	return nil
+}
+
+func (b *memoryBroker) Close() error { return nil }
+
+func (b *memoryBroker) reader(topic string) reader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &memoryReader{broker: b, topic: topic, next: b.committed[topic]}
+}
+
+func (b *memoryBroker) committedOffset(topic string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed[topic]
+}
+
+type memoryReader struct {
+	broker *memoryBroker
+	topic  string
+	next   int64
+}
+
+func (r *memoryReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		r.broker.mu.Lock()
+		log := r.broker.logs[r.topic]
+		r.broker.mu.Unlock()
+
+		if r.next < int64(len(log)) {
+			msg := log[r.next]
+			r.next++
+			return msg, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func (r *memoryReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.broker.mu.Lock()
+	defer r.broker.mu.Unlock()
+	for _, msg := range msgs {
+		r.broker.committed[msg.Topic] = msg.Offset + 1
+	}
+	return nil
+}
+
+func (r *memoryReader) Close() error { return nil }
+
+func TestKafkaBus_CommitsAcknowledgedPrefix(t *testing.T) {
+	broker := newMemoryBroker()
+	b := newKafkaBus(appcontext.AppContext{Log: logger.NewLogger()}, broker, broker.reader)
+	defer b.Close()
+
+	for _, payload := range []string{"post-0", "post-1", "post-2"} {
+		if err := b.Publish("blueskyfh-messages/pb", []byte(payload)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	received := make(chan interfaces.BusMessage, 3)
+	if err := b.Subscribe("blueskyfh-messages/pb", func(msg interfaces.BusMessage) {
+		received <- msg
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var messages []interfaces.BusMessage
+	for range 3 {
+		select {
+		case msg := <-received:
+			messages = append(messages, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a message")
+		}
+	}
+	if messages[0].Topic() != "blueskyfh-messages/pb" || string(messages[2].Payload()) != "post-2" {
+		t.Errorf("Unexpected message %s: %s", messages[0].Topic(), messages[2].Payload())
+	}
+
+	const topic = "blueskyfh-messages.pb"
+
+	// Acknowledged out of order, the offset only moves past contiguous messages.
+	messages[1].Ack()
+	if offset := broker.committedOffset(topic); offset != 0 {
+		t.Errorf("Expected nothing committed while post-0 is processed, got offset %d", offset)
+	}
+	messages[0].Ack()
+	if offset := broker.committedOffset(topic); offset != 2 {
+		t.Errorf("Expected offset 2 once post-0 and post-1 are acknowledged, got %d", offset)
+	}
+
+	// A restarted subscriber resumes after the committed messages.
+	if err := b.Unsubscribe("blueskyfh-messages/pb"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := b.Subscribe("blueskyfh-messages/pb", func(msg interfaces.BusMessage) {
+		received <- msg
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	select {
+	case msg := <-received:
+		if string(msg.Payload()) != "post-2" {
+			t.Errorf("Expected the unacknowledged post-2 again, got %s", msg.Payload())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the redelivery")
+	}
+}
+
+func TestTopic(t *testing.T) {
+	testCases := []struct {
+		topic    string
+		expected string
+	}{
+		{topic: "blueskyfh-messages", expected: "blueskyfh-messages"},
+		{topic: "blueskyfh-messages/pb", expected: "blueskyfh-messages.pb"},
+		{topic: "blueskyfh/classified/arts & culture", expected: "blueskyfh.classified.arts___culture"},
+	}
+
+	for _, tc := range testCases {
+		if name := Topic(tc.topic); name != tc.expected {
+			t.Errorf("Expected %s for %s, got %s", tc.expected, tc.topic, name)
+		}
+	}
+}
+
+func TestOffsetTracker_Partitions(t *testing.T) {
+	tracker := newOffsetTracker()
+	for _, msg := range []kafka.Message{
+		{Partition: 0, Offset: 10},
+		{Partition: 1, Offset: 4},
+		{Partition: 0, Offset: 11},
+	} {
+		tracker.Fetched(msg)
+	}
+
+	var committed []int64
+	for _, msg := range []kafka.Message{
+		{Partition: 0, Offset: 11},
+		{Partition: 1, Offset: 4},
+		{Partition: 0, Offset: 10},
+	} {
+		if next, ok := tracker.Acked(msg); ok {
+			committed = append(committed, next.Offset)
+		}
+	}
+	if !slices.Equal(committed, []int64{4, 11}) {
+		t.Errorf("Expected offsets 4 and 11 to be committed, got %v", committed)
+	}
+}
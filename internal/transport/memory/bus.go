@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+)
+
+// ErrClosed is returned when publishing to or subscribing on a closed bus.
+var ErrClosed = errors.New("memory bus is closed")
+
+// MemoryBus is an in-process message bus connecting the services of a single binary
+// through channels, so the pipeline runs without a broker. A topic is delivered to
+// the handler subscribed to it, one message at a time; messages published to a topic
+// nobody subscribed to are dropped, like an MQTT broker drops unretained messages.
+type MemoryBus struct {
+	queueSize int
+
+	mu            sync.Mutex
+	closed        bool
+	subscriptions map[string]*subscription
+}
+
+// Ensure MemoryBus implements the interfaces.Bus interface.
+var _ interfaces.Bus = (*MemoryBus)(nil)
+
+// NewMemoryBus creates a MemoryBus buffering up to queueSize messages per topic.
+// Publishing blocks while the buffer of a topic is full, so a slow subscriber slows
+// down the publisher instead of messages piling up in memory.
+func NewMemoryBus(queueSize int) *MemoryBus {
+	return &MemoryBus{
+		queueSize:     max(queueSize, 0),
+		subscriptions: make(map[string]*subscription),
+	}
+}
+
+// IsConnected reports whether the bus is open.
+func (b *MemoryBus) IsConnected() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.closed
+}
+
+// Publish hands a copy of data to the subscriber of topic, waiting for room in its
+// buffer.
+func (b *MemoryBus) Publish(topic string, data []byte) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	sub, ok := b.subscriptions[topic]
+	b.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	// The publisher may reuse its buffer once Publish returns.
+	msg := &message{topic: topic, data: append([]byte(nil), data...)}
+	select {
+	case sub.queue <- msg:
+	case <-sub.done:
+		// Unsubscribed while waiting, the message is dropped like any other message
+		// to a topic without subscriber.
+	}
+	return nil
+}
+
+// Subscribe delivers the messages published to topic to handler, replacing the
+// previous handler of topic.
+func (b *MemoryBus) Subscribe(topic string, handler func(interfaces.BusMessage)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	if previous, ok := b.subscriptions[topic]; ok {
+		previous.stop()
+	}
+
+	sub := &subscription{
+		queue: make(chan *message, b.queueSize),
+		done:  make(chan struct{}),
+	}
+	b.subscriptions[topic] = sub
+	go sub.run(handler)
+	return nil
+}
+
+// Unsubscribe stops delivering topic, the messages still buffered are dropped.
+func (b *MemoryBus) Unsubscribe(topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub, ok := b.subscriptions[topic]; ok {
+		sub.stop()
+		delete(b.subscriptions, topic)
+	}
+	return nil
+}
+
+// Close stops every subscription, later publishes fail with ErrClosed.
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for topic, sub := range b.subscriptions {
+		sub.stop()
+		delete(b.subscriptions, topic)
+	}
+	return nil
+}
+
+// subscription delivers the buffered messages of a topic until stopped.
+type subscription struct {
+	queue    chan *message
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (s *subscription) run(handler func(interfaces.BusMessage)) {
+	for {
+		// Checked first so a stopped subscription does not deliver buffered messages.
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		select {
+		case msg := <-s.queue:
+			handler(msg)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *subscription) stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+// message is a message published on the memory bus. Nothing is redelivered, so
+// acknowledging it is a no-op.
+type message struct {
+	topic string
+	data  []byte
+}
+
+func (m *message) Topic() string   { return m.topic }
+func (m *message) Payload() []byte { return m.data }
+func (m *message) Ack()            {}
@@ -0,0 +1,130 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+)
+
+func receive(t *testing.T, ch <-chan interfaces.BusMessage) interfaces.BusMessage {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a message")
+		return nil
+	}
+}
+
+func TestMemoryBus_Delivery(t *testing.T) {
+	b := NewMemoryBus(10)
+	defer b.Close()
+
+	// Nobody subscribed yet, the message is dropped.
+	if err := b.Publish("blueskyfh-messages", []byte("dropped")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	received := make(chan interfaces.BusMessage, 10)
+	if err := b.Subscribe("blueskyfh-messages", func(msg interfaces.BusMessage) {
+		received <- msg
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data := []byte("post-1")
+	if err := b.Publish("blueskyfh-messages", data); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data[0] = 'X' // The bus keeps its own copy
+
+	msg := receive(t, received)
+	if msg.Topic() != "blueskyfh-messages" || string(msg.Payload()) != "post-1" {
+		t.Errorf("Unexpected message %s: %s", msg.Topic(), msg.Payload())
+	}
+	if err := b.Publish("blueskyfh-metrics", []byte("metrics")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	select {
+	case msg := <-received:
+		t.Errorf("Unexpected message on %s", msg.Topic())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryBus_Backpressure(t *testing.T) {
+	b := NewMemoryBus(1)
+	defer b.Close()
+
+	release := make(chan struct{})
+	if err := b.Subscribe("blueskyfh-messages", func(interfaces.BusMessage) {
+		<-release
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// One message in the handler and one buffered, the third publish waits.
+	published := make(chan struct{})
+	go func() {
+		for range 3 {
+			_ = b.Publish("blueskyfh-messages", []byte("post"))
+		}
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("Expected the publisher to wait for the subscriber")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the publisher")
+	}
+}
+
+func TestMemoryBus_Close(t *testing.T) {
+	b := NewMemoryBus(1)
+
+	blocked := make(chan struct{})
+	if err := b.Subscribe("blueskyfh-messages", func(interfaces.BusMessage) {
+		<-blocked
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer close(blocked)
+
+	// A publisher waiting for room is released by Close.
+	published := make(chan error, 1)
+	go func() {
+		var err error
+		for range 3 {
+			err = b.Publish("blueskyfh-messages", []byte("post"))
+		}
+		published <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := b.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the publisher")
+	}
+
+	if b.IsConnected() {
+		t.Error("Expected the bus to be disconnected")
+	}
+	if err := b.Publish("blueskyfh-messages", []byte("post")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
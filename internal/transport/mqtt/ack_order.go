@@ -0,0 +1,66 @@
+package mqtt
+
+import (
+	"sync"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// ackOrder acknowledges received messages in the order they arrived. The workers
+// finish messages out of order, but MQTT requires the PUBACKs of a connection in the
+// order its PUBLISH packets were received, so an acknowledged message waits for the
+// messages received before it.
+type ackOrder struct {
+	mu      sync.Mutex
+	pending []*orderedMessage // received and not acknowledged, in arrival order
+	session int
+}
+
+// orderedMessage is a received message whose Ack goes through its ackOrder.
+type orderedMessage struct {
+	mqtt.Message
+	order   *ackOrder
+	session int
+	acked   bool
+}
+
+// Received records msg and returns it with an Ack keeping the arrival order. QoS 0
+// messages are never acknowledged and are returned as they are.
+func (o *ackOrder) Received(msg mqtt.Message) mqtt.Message {
+	if msg.Qos() == 0 {
+		return msg
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	ordered := &orderedMessage{Message: msg, order: o, session: o.session}
+	o.pending = append(o.pending, ordered)
+	return ordered
+}
+
+// Reset forgets the pending messages when the connection is lost. The broker sends
+// them again, so they are not acknowledged on the new connection.
+func (o *ackOrder) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pending = nil
+	o.session++
+}
+
+// Ack acknowledges the message once every message received before it is.
+func (m *orderedMessage) Ack() {
+	o := m.order
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if m.session != o.session {
+		return
+	}
+	m.acked = true
+	for len(o.pending) > 0 && o.pending[0].acked {
+		o.pending[0].Message.Ack()
+		o.pending = o.pending[1:]
+	}
+}
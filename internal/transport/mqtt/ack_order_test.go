@@ -0,0 +1,55 @@
+package mqtt
+
+import (
+	"slices"
+	"testing"
+)
+
+// fakeMessage records its acknowledgement in acked.
+type fakeMessage struct {
+	id    uint16
+	qos   byte
+	acked *[]uint16
+}
+
+func (m *fakeMessage) Duplicate() bool   { return false }
+func (m *fakeMessage) Qos() byte         { return m.qos }
+func (m *fakeMessage) Retained() bool    { return false }
+func (m *fakeMessage) Topic() string     { return "messages" }
+func (m *fakeMessage) MessageID() uint16 { return m.id }
+func (m *fakeMessage) Payload() []byte   { return nil }
+func (m *fakeMessage) Ack()              { *m.acked = append(*m.acked, m.id) }
+
+func TestAckOrder(t *testing.T) {
+	var acked []uint16
+	var order ackOrder
+
+	first := order.Received(&fakeMessage{id: 1, qos: 1, acked: &acked})
+	second := order.Received(&fakeMessage{id: 2, qos: 1, acked: &acked})
+	third := order.Received(&fakeMessage{id: 3, qos: 1, acked: &acked})
+
+	third.Ack()
+	second.Ack()
+	if len(acked) != 0 {
+		t.Fatalf("Expected no acknowledgement before the first message's, got %v", acked)
+	}
+	first.Ack()
+	if !slices.Equal(acked, []uint16{1, 2, 3}) {
+		t.Errorf("Expected acknowledgements in arrival order, got %v", acked)
+	}
+
+	// Messages of a lost connection are delivered again, not acknowledged.
+	acked = nil
+	lost := order.Received(&fakeMessage{id: 4, qos: 1, acked: &acked})
+	order.Reset()
+	redelivered := order.Received(&fakeMessage{id: 4, qos: 1, acked: &acked})
+	lost.Ack()
+	redelivered.Ack()
+	if !slices.Equal(acked, []uint16{4}) {
+		t.Errorf("Expected only the redelivered message to be acknowledged, got %v", acked)
+	}
+
+	if msg := order.Received(&fakeMessage{id: 5, acked: &acked}); msg.MessageID() != 5 || len(order.pending) != 0 {
+		t.Error("Expected QoS 0 messages not to be tracked")
+	}
+}
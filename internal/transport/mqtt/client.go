@@ -2,71 +2,93 @@ package mqtt
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
-	"google.golang.org/protobuf/encoding/protojson"
-	"google.golang.org/protobuf/proto"
 	"stockseer.ai/blueksy-firehose/internal/appcontext"
 	"stockseer.ai/blueksy-firehose/internal/config" // Assuming config.AppConfig is defined here
-	"stockseer.ai/blueksy-firehose/internal/domain"
-	"stockseer.ai/blueksy-firehose/internal/interfaces" // Assuming interfaces.MqttClient is defined here
-	"stockseer.ai/blueksy-firehose/internal/models"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
 )
 
-// MqttClient encapsulates the MQTT client and its associated state.
+// publishTimeout bounds the wait for the broker to acknowledge a publish. Publishing
+// from a message handler waits for an acknowledgement that can queue behind messages
+// waiting for the handler to return, so it is not waited for indefinitely.
+const publishTimeout = 10 * time.Second
+
+// MqttClient is the MQTT backend of the message bus.
 type MqttClient struct {
-	client     mqtt.Client
-	appCtx     appcontext.AppContext
-	processors *domain.TextProcessorFactory
-	config     *config.AppConfig // Corrected type to direct pointer if it's already a pointer in AppContext
-	clientID   string
-
-	desiredSubscriptions map[string]byte
-	mu                   sync.Mutex // Mutex to protect access to client and desiredSubscriptions
+	client mqtt.Client
+	appCtx appcontext.AppContext
+	config *config.AppConfig // Corrected type to direct pointer if it's already a pointer in AppContext
+
+	desiredSubscriptions map[string]func(interfaces.BusMessage)
+	topicOptions         map[string]TopicOptions
+	routeOptions         TopicOptions // Topics that are not configured, the routed ones
+	acks                 ackOrder     // Acknowledges received messages in arrival order
+	mu                   sync.Mutex   // Mutex to protect access to client and desiredSubscriptions
 	isConnected          bool
-	dataCollector        *domain.DataCollector // Moved data collector to the MqttClient
-
-	// Channel to signal that the initial connection has been established.
-	// This helps synchronize the main application with the MQTT connection.
-	connectOnce sync.Once
-	connectedCh chan struct{}
 }
 
-// Ensure MqttClient implements the interfaces.MqttClient interface.
-var _ interfaces.MqttClient = (*MqttClient)(nil)
-
-// NewMqttClient creates and initializes a new MqttClient instance.
-func NewMqttClient(
-	appCtx appcontext.AppContext,
-	clientID string,
-	processors *domain.TextProcessorFactory,
-) (*MqttClient, error) {
-	if !appCtx.Config.MQTTEnabled {
-		appCtx.Log.Info("MQTT is disabled in configuration")
-		// Consider returning an error here if MQTT being disabled is a critical misconfiguration
-		return nil, nil
+// Ensure MqttClient implements the interfaces.Bus interface.
+var _ interfaces.Bus = (*MqttClient)(nil)
+
+// NewMqttClient creates an MqttClient connecting with clientID. The connection is
+// established in the background and re-established when lost.
+func NewMqttClient(appCtx appcontext.AppContext, clientID string) (*MqttClient, error) {
+	cfg := &appCtx.Config
+
+	topicOptions, err := topicOptionsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MQTTRouteQoS < 0 || cfg.MQTTRouteQoS > 2 {
+		return nil, fmt.Errorf("invalid QoS %d for routed MQTT topics", cfg.MQTTRouteQoS)
+	}
+	if _, err := subscriptionTopic(cfg.BusMessagesTopic, cfg.MQTTSharedGroup); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	mc := &MqttClient{
 		appCtx:               appCtx,
-		processors:           processors,
-		config:               &appCtx.Config, // Assuming appCtx.Config is already *config.AppConfig
-		clientID:             clientID,
-		desiredSubscriptions: make(map[string]byte),
-		dataCollector:        domain.NewDataCollector(appCtx),
-		connectedCh:          make(chan struct{}), // Initialize the channel
+		config:               cfg,
+		desiredSubscriptions: make(map[string]func(interfaces.BusMessage)),
+		topicOptions:         topicOptions,
+		routeOptions: TopicOptions{
+			QoS:    byte(cfg.MQTTRouteQoS),
+			Retain: cfg.MQTTRouteRetain,
+		},
 	}
 
 	broker := mc.config.MQTTBrokerURL
 	options := mqtt.NewClientOptions().AddBroker(broker)
-	options.SetClientID("bluesky-client-" + mc.config.MQTTUsername + "-" + clientID)
+	options.SetClientID(clientID)
 	options.SetUsername(mc.config.MQTTUsername)
 	options.SetPassword(mc.config.MQTTPassword)
 
+	if tlsConfig != nil {
+		options.SetTLSConfig(tlsConfig)
+	}
+
+	// A persistent session keeps QoS 1/2 messages queued on the broker while the
+	// client is away, which requires a stable client id.
+	options.SetCleanSession(mc.config.MQTTCleanSession)
+	options.SetResumeSubs(!mc.config.MQTTCleanSession)
+	if mc.config.MQTTStoreDir != "" {
+		// Keeps in-flight messages across restarts, the default store is in memory.
+		options.SetStore(mqtt.NewFileStore(mc.config.MQTTStoreDir))
+	}
+
+	// Messages are acknowledged once processed, so the ones still queued or in flight
+	// when the client stops are redelivered to a persistent session.
+	options.SetAutoAckDisabled(true)
+
 	options.SetKeepAlive(60 * time.Second)
 	options.SetConnectTimeout(30 * time.Second)
 
@@ -77,23 +99,17 @@ func NewMqttClient(
 
 	mc.client = mqtt.NewClient(options)
 
-	// Start metrics collection once when the client is initialized
-	if metricsErr := mc.dataCollector.StartMetrics(appCtx, true); metricsErr != nil {
-		appCtx.Log.Error("failed to start metrics collection...", metricsErr)
-		// Decide if this is a fatal error or just a warning for your application
-	}
-
 	appCtx.Log.Info("Connecting to MQTT broker...")
 	appCtx.Log.Info("Broker URL: %s", broker)
 	appCtx.Log.Info("Username: %s", mc.config.MQTTUsername)
-	appCtx.Log.Info("Metrics Topic: %s", mc.config.MQTTMetricsTopic)
-	appCtx.Log.Info("Messages Topic: %s", mc.config.MQTTMessagesTopic)
+	appCtx.Log.Info("Client ID: %s", clientID)
+	appCtx.Log.Info("TLS: %t, Clean Session: %t", tlsConfig != nil, mc.config.MQTTCleanSession)
 
 	// Connect asynchronously to avoid blocking NewMqttClient
 	// The `onConnect` callback will handle the initial subscriptions.
 	go func() {
 		if token := mc.client.Connect(); token.Wait() && token.Error() != nil {
-			mc.appCtx.Log.Error("Initial MQTT connection failed: %v", token.Error())
+			mc.appCtx.Log.Error("Initial MQTT connection failed", token.Error())
 			// This goroutine could potentially block or retry here if you want to
 			// make connection a blocking prerequisite for NewMqttClient success.
 			// For now, it just logs and relies on AutoReconnect.
@@ -108,31 +124,29 @@ func (mc *MqttClient) onConnect(c mqtt.Client) {
 	mc.appCtx.Log.Info("Connected to MQTT broker")
 	mc.mu.Lock()
 	mc.isConnected = true
+	handlers := make(map[string]func(interfaces.BusMessage), len(mc.desiredSubscriptions))
+	for topic, handler := range mc.desiredSubscriptions {
+		handlers[topic] = handler
+	}
+	mc.mu.Unlock()
+
 	// Resubscribe to all desired topics
-	for topic, qos := range mc.desiredSubscriptions {
+	for topic, handler := range handlers {
 		mc.appCtx.Log.Info("Resubscribing to topic: %s", topic)
-		// It's crucial that subscribeToTopic internally handles the actual subscription.
-		// We pass the already-bound messageHandler for the topic.
-		if err := mc.subscribeInternal(topic, qos, mc.messageHandler(topic)); err != nil {
+		if err := mc.subscribeInternal(topic, handler); err != nil {
 			msg := fmt.Sprintf("Failed to resubscribe to topic %s: %v", topic, err)
 			mc.appCtx.Log.Error(msg, err)
 		}
 	}
-	mc.mu.Unlock()
-
-	// Signal that the initial connection has been made
-	// This ensures `connectedCh` is closed exactly once.
-	mc.connectOnce.Do(func() {
-		close(mc.connectedCh)
-	})
 }
 
 // onConnectionLost is the callback executed when the MQTT client loses its connection.
 func (mc *MqttClient) onConnectionLost(c mqtt.Client, err error) {
-	mc.appCtx.Log.Error("Connection lost: %v", err)
+	mc.appCtx.Log.Error("Connection lost", err)
 	mc.mu.Lock()
 	mc.isConnected = false
 	mc.mu.Unlock()
+	mc.acks.Reset()
 	// Auto-reconnect will handle re-establishing the connection.
 	// No explicit reconnect loop needed here.
 }
@@ -144,8 +158,8 @@ func (mc *MqttClient) IsConnected() bool {
 	return mc.isConnected && mc.client.IsConnected()
 }
 
-// Disconnect disconnects the MQTT client.
-func (mc *MqttClient) Disconnect() {
+// Close disconnects the MQTT client.
+func (mc *MqttClient) Close() error {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
 
@@ -154,141 +168,107 @@ func (mc *MqttClient) Disconnect() {
 		mc.client.Disconnect(250)
 		mc.isConnected = false
 	}
+	return nil
 }
 
-// PublishToMQTT publishes a protobuf message to the specified topic.
-func (mc *MqttClient) PublishToMQTT(topic string, msg *models.ProtoMessage) error {
-	if !mc.IsConnected() {
-		return fmt.Errorf("MQTT client not connected, cannot publish to topic %s", topic)
-	}
+// TopicOptions returns the QoS and retain settings of topic. The subtopics of the
+// metrics topic share its settings and other topics use the routed topics' settings.
+func (mc *MqttClient) TopicOptions(topic string) TopicOptions {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
 
-	data, err := proto.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("error marshaling protobuf message: %w", err)
+	if opts, ok := mc.topicOptions[topic]; ok {
+		return opts
 	}
-
-	if token := mc.client.Publish(topic, byte(0), false, data); token.Wait() &&
-		token.Error() != nil {
-		return fmt.Errorf("error publishing to MQTT topic %s: %w", topic, token.Error())
+	if strings.HasPrefix(topic, mc.config.BusMetricsTopic+"/") {
+		return mc.topicOptions[mc.config.BusMetricsTopic]
 	}
-	return nil
+	return mc.routeOptions
+}
+
+// SetTopicOptions overrides the QoS and retain settings of topic.
+func (mc *MqttClient) SetTopicOptions(topic string, opts TopicOptions) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.topicOptions[topic] = opts
 }
 
-// PublishJSONToMQTT publishes a JSON string to the specified topic.
-func (mc *MqttClient) PublishJSONToMQTT(topic string, msg string) error {
+// Publish publishes data to topic with the topic's QoS and retain settings.
+func (mc *MqttClient) Publish(topic string, data []byte) error {
 	if !mc.IsConnected() {
 		return fmt.Errorf("MQTT client not connected, cannot publish to topic %s", topic)
 	}
 
-	if token := mc.client.Publish(topic, 0, false, []byte(msg)); token.Wait() &&
-		token.Error() != nil {
-		return fmt.Errorf("error publishing JSON to MQTT topic %s: %w", topic, token.Error())
+	opts := mc.TopicOptions(topic)
+	token := mc.client.Publish(topic, opts.QoS, opts.Retain, data)
+	if !token.WaitTimeout(publishTimeout) {
+		return fmt.Errorf("timed out publishing to MQTT topic %s", topic)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("error publishing to MQTT topic %s: %w", topic, err)
 	}
 	return nil
 }
 
-// processMessage handles the processing of an MQTT message and returns the processed message.
-func (mc *MqttClient) processMessage(msg mqtt.Message) *models.ProtoMessage {
-	jsonStr := string(msg.Payload())
-
-	var protoMessage models.ProtoMessage
-	if err := protojson.Unmarshal([]byte(jsonStr), &protoMessage); err != nil {
-		mc.appCtx.Log.Error("Error parsing JSON message: %v", err)
-		return nil
-	}
-
-	processed, err := mc.processors.ProcessAll(protoMessage.Commit.Record.Text)
-	if err != nil {
-		mc.appCtx.Log.Error("Failed to process message: %v", err)
-		return nil
-	}
-
-	cleaned := regexp.MustCompile(`[\W_]+`).
-		ReplaceAllString(processed["TextCategoryClassifier"], " ")
-	protoMessage.Categories = strings.Fields(cleaned)
-
-	finSentiment := processed["TextFinSentimentClassifier"]
-	protoMessage.FinSentiment = &finSentiment
-
-	if domain.ShouldStoreMessage(&protoMessage) {
-		if err := mc.appCtx.MessageRepo.Insert(&protoMessage); err != nil {
-			mc.appCtx.Log.Error("Failed to insert message: %v", err)
-		}
+// subscriptionFilter returns the filter topic is subscribed with. Only the messages
+// topics are shared by the group, other subscribers, such as the replay command
+// reading the dead letter topic, receive every message.
+func (mc *MqttClient) subscriptionFilter(topic string) (string, error) {
+	if topic != mc.config.BusMessagesTopic && topic != mc.config.BusMessagesTopic+"/pb" {
+		return topic, nil
 	}
-	return &protoMessage
+	return subscriptionTopic(topic, mc.config.MQTTSharedGroup)
 }
 
-// messageHandler creates a message handler for a specific topic.
-func (mc *MqttClient) messageHandler(topic string) mqtt.MessageHandler {
-	return func(client mqtt.Client, msg mqtt.Message) {
-		protoMessage := mc.processMessage(msg)
-		if protoMessage != nil {
-			if err := mc.dataCollector.Add(protoMessage); err != nil {
-				mc.appCtx.Log.Error("Failed to add message to data collector: %v", err)
-			}
-		}
+// subscribeInternal is the internal function that performs the MQTT subscription.
+// Replicas in the same shared group split the messages between them instead of each
+// receiving every message.
+func (mc *MqttClient) subscribeInternal(topic string, handler func(interfaces.BusMessage)) error {
+	filter, err := mc.subscriptionFilter(topic)
+	if err != nil {
+		return err
 	}
-}
 
-// subscribeInternal is the internal function that performs the MQTT subscription.
-// It assumes the mutex is already locked if called from onConnect, or locks it if called directly.
-func (mc *MqttClient) subscribeInternal(topic string, qos byte, handler mqtt.MessageHandler) error {
-	if token := mc.client.Subscribe(topic, qos, handler); token.Wait() && token.Error() != nil {
-		return fmt.Errorf("failed to subscribe to topic %s: %w", topic, token.Error())
+	callback := func(_ mqtt.Client, msg mqtt.Message) { handler(mc.acks.Received(msg)) }
+	qos := mc.TopicOptions(topic).QoS
+	if token := mc.client.Subscribe(filter, qos, callback); token.Wait() && token.Error() != nil {
+		return fmt.Errorf("failed to subscribe to topic %s: %w", filter, token.Error())
 	}
-	mc.appCtx.Log.Info("Successfully subscribed to topic: %s", topic)
+	mc.appCtx.Log.Info("Successfully subscribed to topic: %s", filter)
 	return nil
 }
 
-// Subscribe adds a topic to the desired subscriptions and attempts to subscribe if connected.
-// This is the public method for external calls to subscribe.
-func (mc *MqttClient) Subscribe(topic string, qos byte) error {
+// Subscribe adds a topic to the desired subscriptions and attempts to subscribe if
+// connected. Otherwise, onConnect subscribes once the client is connected.
+func (mc *MqttClient) Subscribe(topic string, handler func(interfaces.BusMessage)) error {
 	mc.mu.Lock()
 	// Always add to desired subscriptions, so it's picked up on reconnect
-	mc.desiredSubscriptions[topic] = qos
+	mc.desiredSubscriptions[topic] = handler
 	mc.mu.Unlock()
 
-	// Create handler specific to this topic
-	handler := mc.messageHandler(topic)
-
-	// Attempt to subscribe immediately if already connected.
-	// Otherwise, onConnect will handle it.
-	if mc.IsConnected() { // Uses IsConnected to safely check connection status
-		mc.mu.Lock() // Lock before calling internal subscribe method
-		defer mc.mu.Unlock()
-		return mc.subscribeInternal(topic, qos, handler)
-	} else {
+	if !mc.IsConnected() {
 		mc.appCtx.Log.Warn("Not currently connected. Topic %s will be subscribed on successful MQTT connection.", topic)
 		return nil // Not an error, just means subscription will happen later
 	}
+	return mc.subscribeInternal(topic, handler)
 }
 
-// ConsumeMessages sets up the subscription for consuming messages.
-// This function should ideally just *register* the topic for consumption.
-// The actual subscription happens in onConnect.
-func (mc *MqttClient) ConsumeMessages() error {
-	topic := mc.config.MQTTMessagesTopic
-	qos := byte(1) // QoS 1 for "at least once" delivery
-
-	// Register the subscription. The Subscribe method will handle the actual logic.
-	// It will add to desiredSubscriptions and attempt immediate subscription if connected.
-	if err := mc.Subscribe(topic, qos); err != nil {
-		return fmt.Errorf("failed to register message consumption for topic %s: %w", topic, err)
-	}
-
-	mc.appCtx.Log.Info("MQTT message consumption setup initiated for topic: %s", topic)
+// Unsubscribe removes a topic from the desired subscriptions and unsubscribes from it.
+func (mc *MqttClient) Unsubscribe(topic string) error {
+	mc.mu.Lock()
+	delete(mc.desiredSubscriptions, topic)
+	mc.mu.Unlock()
 
-	// Wait for the initial connection to be established before proceeding.
-	// This helps ensure subscriptions are registered before the main loop starts.
-	select {
-	case <-mc.connectedCh:
-		mc.appCtx.Log.Info("Initial MQTT connection established, consumer is active.")
-	case <-time.After(30 * time.Second): // Add a timeout for the initial connection
-		return fmt.Errorf("timed out waiting for initial MQTT connection for consumer")
+	if !mc.IsConnected() {
+		return nil
 	}
 
-	// Keep the function running to listen for messages.
-	// This will block forever, which is typical for a long-running service.
-	// It's important that this is called in a goroutine if your main function needs to do other things.
-	select {}
+	filter, err := mc.subscriptionFilter(topic)
+	if err != nil {
+		return err
+	}
+	if token := mc.client.Unsubscribe(filter); token.Wait() && token.Error() != nil {
+		return fmt.Errorf("failed to unsubscribe from topic %s: %w", filter, token.Error())
+	}
+	return nil
 }
@@ -0,0 +1,110 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+)
+
+// TopicOptions are the delivery settings used when publishing to or subscribing
+// to a topic.
+type TopicOptions struct {
+	QoS    byte
+	Retain bool
+}
+
+// topicOptionsFromConfig maps the configured topics to their settings.
+func topicOptionsFromConfig(cfg *config.AppConfig) (map[string]TopicOptions, error) {
+	topics := make(map[string]TopicOptions)
+
+	for _, topic := range []struct {
+		name   string
+		qos    int
+		retain bool
+	}{
+		{cfg.BusMessagesTopic, cfg.MQTTMessagesQoS, cfg.MQTTMessagesRetain},
+		{cfg.BusMetricsTopic, cfg.MQTTMetricsQoS, cfg.MQTTMetricsRetain},
+		// Dead letters are worth the delivery guarantees of the messages they hold.
+		{cfg.DeadLetterTopic, cfg.MQTTMessagesQoS, false},
+	} {
+		if topic.qos < 0 || topic.qos > 2 {
+			return nil, fmt.Errorf("invalid QoS %d for MQTT topic %s", topic.qos, topic.name)
+		}
+		if topic.name != "" {
+			topics[topic.name] = TopicOptions{QoS: byte(topic.qos), Retain: topic.retain}
+		}
+	}
+
+	// Both encodings of the messages topic are delivered the same way, protobuf is
+	// published on the topic with a "/pb" suffix.
+	if opts, ok := topics[cfg.BusMessagesTopic]; ok {
+		topics[cfg.BusMessagesTopic+"/pb"] = opts
+	}
+
+	return topics, nil
+}
+
+// usesTLS reports whether the broker url needs a TLS connection.
+func usesTLS(brokerURL string) bool {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "ssl", "tls", "mqtts", "tcps", "wss":
+		return true
+	}
+	return false
+}
+
+// newTLSConfig builds the TLS settings for the broker connection. It returns nil
+// when the broker is not a TLS url and no certificates are configured.
+func newTLSConfig(cfg *config.AppConfig) (*tls.Config, error) {
+	if !usesTLS(cfg.MQTTBrokerURL) && cfg.MQTTCAFile == "" && cfg.MQTTCertFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.MQTTTLSInsecureSkipVerify, //nolint:gosec // opt-in for test brokers
+	}
+
+	if cfg.MQTTCAFile != "" {
+		pem, err := os.ReadFile(cfg.MQTTCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading MQTT CA file %s: %w", cfg.MQTTCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in MQTT CA file %s", cfg.MQTTCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.MQTTCertFile != "" || cfg.MQTTKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.MQTTCertFile, cfg.MQTTKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading MQTT client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// subscriptionTopic returns the filter consumers subscribe with. With a group every
+// message is delivered to only one of the group's subscribers.
+func subscriptionTopic(topic string, group string) (string, error) {
+	if group == "" {
+		return topic, nil
+	}
+	if strings.ContainsAny(group, "/+#") {
+		return "", fmt.Errorf("invalid MQTT shared subscription group %q", group)
+	}
+	return "$share/" + group + "/" + topic, nil
+}
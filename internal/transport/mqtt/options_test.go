@@ -0,0 +1,126 @@
+package mqtt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stockseer.ai/blueksy-firehose/internal/config"
+)
+
+func TestTopicOptionsFromConfig(t *testing.T) {
+	cfg := &config.AppConfig{
+		BusMessagesTopic:   "messages",
+		MQTTMessagesQoS:    1,
+		BusMetricsTopic:    "metrics",
+		MQTTMetricsQoS:     0,
+		MQTTMetricsRetain:  true,
+		MQTTMessagesRetain: false,
+	}
+
+	topics, err := topicOptionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if topics["messages"] != (TopicOptions{QoS: 1}) {
+		t.Errorf("Unexpected messages options: %+v", topics["messages"])
+	}
+	if topics["metrics"] != (TopicOptions{QoS: 0, Retain: true}) {
+		t.Errorf("Unexpected metrics options: %+v", topics["metrics"])
+	}
+
+	mc := &MqttClient{config: cfg, topicOptions: topics, routeOptions: TopicOptions{QoS: 2}}
+	if opts := mc.TopicOptions("metrics/bluesky-client-server-host"); opts != topics["metrics"] {
+		t.Errorf("Expected a client's metrics subtopic to use the metrics options, got %+v", opts)
+	}
+	if opts := mc.TopicOptions("metricsx"); opts != mc.routeOptions {
+		t.Errorf("Expected other topics to use the route options, got %+v", opts)
+	}
+
+	cfg.MQTTMetricsQoS = 3
+	if _, err := topicOptionsFromConfig(cfg); err == nil {
+		t.Error("Expected an error for QoS 3")
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     config.AppConfig
+		enabled bool
+	}{
+		{name: "Plain", cfg: config.AppConfig{MQTTBrokerURL: "tcp://localhost:1883"}},
+		{name: "SSL", cfg: config.AppConfig{MQTTBrokerURL: "ssl://localhost:8883"}, enabled: true},
+		{name: "Secure websocket", cfg: config.AppConfig{MQTTBrokerURL: "wss://broker/mqtt"}, enabled: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig, err := newTLSConfig(&tc.cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (tlsConfig != nil) != tc.enabled {
+				t.Errorf("Expected TLS enabled %t, got %v", tc.enabled, tlsConfig)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfig_InvalidFiles(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cfg := range []config.AppConfig{
+		{MQTTBrokerURL: "ssl://localhost:8883", MQTTCAFile: caFile},
+		{MQTTBrokerURL: "ssl://localhost:8883", MQTTCAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{MQTTBrokerURL: "ssl://localhost:8883", MQTTCertFile: caFile, MQTTKeyFile: caFile},
+	} {
+		if _, err := newTLSConfig(&cfg); err == nil {
+			t.Errorf("Expected an error for %+v", cfg)
+		}
+	}
+}
+
+func TestSubscriptionTopic(t *testing.T) {
+	testCases := []struct {
+		group    string
+		expected string
+		wantErr  bool
+	}{
+		{group: "", expected: "messages"},
+		{group: "classifiers", expected: "$share/classifiers/messages"},
+		{group: "bad/group", wantErr: true},
+		{group: "#", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		topic, err := subscriptionTopic("messages", tc.group)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("Group %q: unexpected error %v", tc.group, err)
+		}
+		if topic != tc.expected {
+			t.Errorf("Group %q: expected %q, got %q", tc.group, tc.expected, topic)
+		}
+	}
+}
+
+func TestSubscriptionFilter(t *testing.T) {
+	mc := &MqttClient{config: &config.AppConfig{BusMessagesTopic: "messages", MQTTSharedGroup: "classifiers"}}
+
+	for topic, expected := range map[string]string{
+		"messages":     "$share/classifiers/messages",
+		"messages/pb":  "$share/classifiers/messages/pb",
+		"dead-letters": "dead-letters",
+	} {
+		filter, err := mc.subscriptionFilter(topic)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if filter != expected {
+			t.Errorf("Expected %q to be subscribed as %q, got %q", topic, expected, filter)
+		}
+	}
+}
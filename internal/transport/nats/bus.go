@@ -0,0 +1,255 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	nats "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+	"stockseer.ai/blueksy-firehose/internal/logger"
+)
+
+// requestTimeout bounds the calls waiting for the JetStream API or a publish
+// acknowledgement.
+const requestTimeout = 10 * time.Second
+
+// NatsBus is the NATS JetStream backend of the message bus. The messages topics and
+// the dead letter topic are kept in a work queue stream and consumed through durable
+// consumers with explicit acknowledgements, a message is removed once acknowledged
+// or NATS_MAX_AGE after it was published. Other topics, such as the metrics and routed
+// topics, are published as plain NATS messages.
+type NatsBus struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	appCtx appcontext.AppContext
+	config *config.AppConfig
+
+	// Subjects stored in the stream.
+	streamSubjects map[string]bool
+
+	mu            sync.Mutex
+	subscriptions map[string]func() // Stops the subscription of a topic
+}
+
+// Ensure NatsBus implements the interfaces.Bus interface.
+var _ interfaces.Bus = (*NatsBus)(nil)
+
+// NewNatsBus connects to NATS_URL as name and creates or updates the stream.
+func NewNatsBus(appCtx appcontext.AppContext, name string) (*NatsBus, error) {
+	cfg := &appCtx.Config
+
+	conn, err := nats.Connect(
+		cfg.NATSURL,
+		nats.Name(name),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				appCtx.Log.Error("Disconnected from NATS server", err)
+			}
+		}),
+		nats.ReconnectHandler(func(_ *nats.Conn) {
+			appCtx.Log.Info("Reconnected to NATS server")
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to NATS server %s: %w", cfg.NATSURL, err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	b := &NatsBus{
+		conn:           conn,
+		js:             js,
+		appCtx:         appCtx,
+		config:         cfg,
+		streamSubjects: make(map[string]bool),
+		subscriptions:  make(map[string]func()),
+	}
+
+	var subjects []string
+	for _, topic := range []string{
+		cfg.BusMessagesTopic,
+		cfg.BusMessagesTopic + "/pb", // The protobuf encoding of the messages
+		cfg.DeadLetterTopic,
+	} {
+		if topic != "" {
+			subject := Subject(topic)
+			b.streamSubjects[subject] = true
+			subjects = append(subjects, subject)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if _, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      cfg.NATSStream,
+		Subjects:  subjects,
+		Retention: jetstream.WorkQueuePolicy,
+		MaxAge:    cfg.NATSMaxAge,
+	}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("creating NATS stream %s: %w", cfg.NATSStream, err)
+	}
+
+	appCtx.Log.Info("Connected to NATS server %s", conn.ConnectedUrlRedacted())
+	appCtx.Log.Info("Stream: %s, Subjects: %s", cfg.NATSStream, strings.Join(subjects, ","))
+	return b, nil
+}
+
+// Subject maps a topic to a NATS subject, the "/" separated levels become "."
+// separated tokens.
+func Subject(topic string) string {
+	return strings.ReplaceAll(topic, "/", ".")
+}
+
+// consumerName returns the durable consumer of subject, shared by the members of
+// the consumer group.
+func consumerName(group string, subject string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', '/', '\\', ' ', '\t':
+			return '_'
+		}
+		return r
+	}, group+"_"+subject)
+}
+
+// IsConnected reports whether the connection to the NATS server is up.
+func (b *NatsBus) IsConnected() bool {
+	return b.conn.IsConnected()
+}
+
+// Publish publishes data to topic, waiting for the stream to store it when the
+// topic is kept in the stream.
+func (b *NatsBus) Publish(topic string, data []byte) error {
+	subject := Subject(topic)
+	if !b.streamSubjects[subject] {
+		if err := b.conn.Publish(subject, data); err != nil {
+			return fmt.Errorf("error publishing to NATS subject %s: %w", subject, err)
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if _, err := b.js.Publish(ctx, subject, data); err != nil {
+		return fmt.Errorf("error publishing to NATS subject %s: %w", subject, err)
+	}
+	return nil
+}
+
+// Subscribe consumes topic. Topics kept in the stream are read through a durable
+// consumer named after NATS_CONSUMER, which its subscribers share, others through a
+// queue group of the same name.
+func (b *NatsBus) Subscribe(topic string, handler func(interfaces.BusMessage)) error {
+	subject := Subject(topic)
+
+	var stop func()
+	if b.streamSubjects[subject] {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		consumer, err := b.js.CreateOrUpdateConsumer(ctx, b.config.NATSStream, jetstream.ConsumerConfig{
+			Durable:       consumerName(b.config.NATSConsumer, subject),
+			FilterSubject: subject,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+		})
+		if err != nil {
+			return fmt.Errorf("creating NATS consumer for %s: %w", subject, err)
+		}
+
+		// Messages are delivered one at a time, so a handler waiting for room in its
+		// queue stops the client from buffering more than the batch pulled.
+		consumeCtx, err := consumer.Consume(
+			func(msg jetstream.Msg) {
+				handler(&streamMessage{topic: topic, msg: msg, log: b.appCtx.Log})
+			},
+			jetstream.PullMaxMessages(max(b.config.BusQueueSize, 1)),
+		)
+		if err != nil {
+			return fmt.Errorf("consuming NATS subject %s: %w", subject, err)
+		}
+		stop = consumeCtx.Stop
+	} else {
+		sub, err := b.conn.QueueSubscribe(subject, b.config.NATSConsumer, func(msg *nats.Msg) {
+			handler(&coreMessage{topic: topic, data: msg.Data})
+		})
+		if err != nil {
+			return fmt.Errorf("subscribing to NATS subject %s: %w", subject, err)
+		}
+		stop = func() { _ = sub.Unsubscribe() }
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if previous, ok := b.subscriptions[topic]; ok {
+		previous()
+	}
+	b.subscriptions[topic] = stop
+
+	b.appCtx.Log.Info("Successfully subscribed to subject: %s", subject)
+	return nil
+}
+
+// Unsubscribe stops consuming topic. The durable consumer is kept, so messages
+// published meanwhile are delivered once subscribed again.
+func (b *NatsBus) Unsubscribe(topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if stop, ok := b.subscriptions[topic]; ok {
+		stop()
+		delete(b.subscriptions, topic)
+	}
+	return nil
+}
+
+// Close stops the subscriptions and closes the connection.
+func (b *NatsBus) Close() error {
+	b.mu.Lock()
+	for topic, stop := range b.subscriptions {
+		stop()
+		delete(b.subscriptions, topic)
+	}
+	b.mu.Unlock()
+
+	b.appCtx.Log.Info("Disconnecting from NATS server...")
+	b.conn.Close()
+	return nil
+}
+
+// streamMessage is a message delivered by a durable consumer.
+type streamMessage struct {
+	topic string
+	msg   jetstream.Msg
+	log   logger.Logger
+}
+
+func (m *streamMessage) Topic() string   { return m.topic }
+func (m *streamMessage) Payload() []byte { return m.msg.Data() }
+
+func (m *streamMessage) Ack() {
+	if err := m.msg.Ack(); err != nil {
+		m.log.Error("Failed to acknowledge NATS message", err)
+	}
+}
+
+// coreMessage is a plain NATS message, which needs no acknowledgement.
+type coreMessage struct {
+	topic string
+	data  []byte
+}
+
+func (m *coreMessage) Topic() string   { return m.topic }
+func (m *coreMessage) Payload() []byte { return m.data }
+func (m *coreMessage) Ack()            {}
@@ -0,0 +1,178 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go/jetstream"
+	"stockseer.ai/blueksy-firehose/internal/appcontext"
+	"stockseer.ai/blueksy-firehose/internal/config"
+	"stockseer.ai/blueksy-firehose/internal/interfaces"
+	"stockseer.ai/blueksy-firehose/internal/logger"
+)
+
+// startServer runs an embedded NATS server with JetStream enabled.
+func startServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+	return ns
+}
+
+func newTestBus(t *testing.T, ns *server.Server) *NatsBus {
+	t.Helper()
+
+	b, err := NewNatsBus(appcontext.AppContext{
+		Config: config.AppConfig{
+			NATSURL:          ns.ClientURL(),
+			NATSStream:       "BLUESKYFH",
+			NATSConsumer:     "servers",
+			BusMessagesTopic: "blueskyfh-messages",
+			DeadLetterTopic:  "blueskyfh-dead-letters",
+			BusQueueSize:     10,
+		},
+		Log: logger.NewLogger(),
+	}, "test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func receive(t *testing.T, ch <-chan interfaces.BusMessage) interfaces.BusMessage {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a message")
+		return nil
+	}
+}
+
+func TestNatsBus_StreamDelivery(t *testing.T) {
+	ns := startServer(t)
+	publisher := newTestBus(t, ns)
+
+	// Published before anyone subscribed, the stream keeps it.
+	if err := publisher.Publish("blueskyfh-messages/pb", []byte("post-1")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	consumer := newTestBus(t, ns)
+	received := make(chan interfaces.BusMessage, 10)
+	if err := consumer.Subscribe("blueskyfh-messages/pb", func(msg interfaces.BusMessage) {
+		received <- msg
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	msg := receive(t, received)
+	if msg.Topic() != "blueskyfh-messages/pb" || string(msg.Payload()) != "post-1" {
+		t.Errorf("Unexpected message %s: %s", msg.Topic(), msg.Payload())
+	}
+	msg.Ack()
+
+	// A second consumer of the group shares the durable consumer, an acknowledged
+	// message is not delivered again.
+	if err := consumer.Unsubscribe("blueskyfh-messages/pb"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	replica := newTestBus(t, ns)
+	if err := replica.Subscribe("blueskyfh-messages/pb", func(msg interfaces.BusMessage) {
+		received <- msg
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := publisher.Publish("blueskyfh-messages/pb", []byte("post-2")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if msg := receive(t, received); string(msg.Payload()) != "post-2" {
+		t.Errorf("Expected only the new message, got %s", msg.Payload())
+	}
+}
+
+func TestNatsBus_CoreSubjects(t *testing.T) {
+	ns := startServer(t)
+	b := newTestBus(t, ns)
+
+	received := make(chan interfaces.BusMessage, 1)
+	if err := b.Subscribe("blueskyfh/classified/economy/negative", func(msg interfaces.BusMessage) {
+		received <- msg
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := b.Publish("blueskyfh/classified/economy/negative", []byte("post")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if msg := receive(t, received); msg.Topic() != "blueskyfh/classified/economy/negative" {
+		t.Errorf("Unexpected topic %s", msg.Topic())
+	}
+}
+
+func TestSubject(t *testing.T) {
+	if subject := Subject("blueskyfh/classified/economy"); subject != "blueskyfh.classified.economy" {
+		t.Errorf("Unexpected subject %s", subject)
+	}
+	if name := consumerName("servers", "blueskyfh-messages.pb"); name != "servers_blueskyfh-messages_pb" {
+		t.Errorf("Unexpected consumer name %s", name)
+	}
+}
+
+func TestNatsBus_AcknowledgedMessagesAreRemoved(t *testing.T) {
+	ns := startServer(t)
+	b := newTestBus(t, ns)
+
+	received := make(chan interfaces.BusMessage, 1)
+	if err := b.Subscribe("blueskyfh-messages", func(msg interfaces.BusMessage) {
+		received <- msg
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := b.Publish("blueskyfh-messages", []byte("post")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	receive(t, received).Ack()
+
+	stream, err := b.js.Stream(context.Background(), "BLUESKYFH")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := stream.Info(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if info.Config.Retention != jetstream.WorkQueuePolicy {
+			t.Fatalf("Expected a work queue stream, got %v", info.Config.Retention)
+		}
+		if info.State.Msgs == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the acknowledged message removed, %d kept", info.State.Msgs)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -16,12 +16,18 @@ import (
 func StartWebSocketClient(
 	ctx context.Context,
 	rules *domain.RuleFactory,
+	dc *domain.DataCollector,
 ) {
 	// get our app config and logger
 	appCtx, _ := appcontext.AppContextFromContext(ctx)
 	cfg := appCtx.Config
 	logger := appCtx.Log
 
+	// metrics are collected across reconnects
+	if metricsErr := dc.StartMetrics(appCtx, false); metricsErr != nil {
+		logger.Error("failed to start metrics collection...", metricsErr)
+	}
+
 	uri := cfg.JetstreamURL
 	for {
 		logger.Info("Connecting to WebSocket...")
@@ -39,7 +45,7 @@ func StartWebSocketClient(
 
 		logger.Info("Connected to WebSocket: %s", uri)
 
-		ConsumeMessages(appCtx, conn, rules)
+		ConsumeMessages(appCtx, conn, rules, dc)
 
 		// If ConsumeMessages returns, it means the connection was closed
 		logger.Info("Connection closed, attempting to reconnect...")
@@ -51,17 +57,10 @@ func ConsumeMessages(
 	appCtx appcontext.AppContext,
 	conn *websocket.Conn,
 	rules *domain.RuleFactory,
+	dc *domain.DataCollector,
 ) {
 	logger := appCtx.Log
 
-	// add data collector for metrics collection
-	dc := domain.DataCollector{}
-	metricsErr := dc.StartMetrics(appCtx, false)
-
-	if metricsErr != nil {
-		logger.Error("failed to start metrics collection...", metricsErr)
-	}
-
 	for {
 		if _, _, err := conn.NextReader(); err != nil {
 			logger.Error("error reading message: %v", err)
@@ -88,14 +87,13 @@ func ConsumeMessages(
 		if m.Commit != nil && m.Commit.Record != nil {
 			text = m.Commit.Record.Text
 		}
-		passed, _ := rules.EvaluateAll(text, &m)
+		passed, results := rules.EvaluateAll(text, &m)
+		dc.AddRuleResults(results)
 		if passed {
 			if err := dc.Add(&m); err != nil {
 				logger.Error("failed to add message", err)
 			}
-			json, _ := m.ToJSON()
-			err := appCtx.MQTTClient.PublishJSONToMQTT(appCtx.Config.MQTTMessagesTopic, json)
-			if err != nil {
+			if err := appCtx.MessageClient.PublishMessage(&m); err != nil {
 				logger.Error("failed to publish message", err)
 			}
 		}
@@ -2,7 +2,7 @@ import os
 
 from flask import Flask, request, jsonify
 from pydantic import BaseModel, ValidationError
-from typing import List
+from typing import List, Optional
 
 from transformers import BertTokenizer, BertForSequenceClassification
 from transformers import pipeline
@@ -25,6 +25,7 @@ class TextItem(BaseModel):
 
 class RequestData(BaseModel):
     items: List[TextItem]
+    top_k: Optional[int] = None
 
 @app.route("/livez", methods=["GET"])
 def health_check():
@@ -40,7 +41,11 @@ def classify_text():
         request_data = RequestData(**data)
         try:
             to_classify = [i.text for i in request_data.items]
-            results = classifier(to_classify)
+            if request_data.top_k:
+                # one list of the k best labels per item
+                results = classifier(to_classify, top_k=request_data.top_k)
+            else:
+                results = classifier(to_classify)
         except Exception as e:
             print(f"Error classifying text: {e}")
             results = [{"error": f"Error classifying text: {e}"}]